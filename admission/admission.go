@@ -0,0 +1,104 @@
+package admission
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+const (
+	// maxRequestSize is the largest AdmissionReview body we'll read. The API server limits
+	// objects to 3MB, so this leaves plenty of headroom.
+	maxRequestSize = 8 * 1024 * 1024
+)
+
+type reviewFunc func(request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse
+
+// Decode an AdmissionReview from the request, pass it to the review function and write the
+// response back
+func serveAdmissionReview(response http.ResponseWriter, request *http.Request, review reviewFunc) {
+	if request.Method != http.MethodPost {
+		response.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Read one byte more than the limit, so that a body over it can be told apart from one
+	// that's exactly at it
+	body, err := ioutil.ReadAll(io.LimitReader(request.Body, maxRequestSize+1))
+	if err != nil {
+		http.Error(response, fmt.Sprintf("could not read request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxRequestSize {
+		http.Error(response, fmt.Sprintf("request body is larger than %d bytes", maxRequestSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	admissionReview := admissionv1.AdmissionReview{}
+	if err := json.Unmarshal(body, &admissionReview); err != nil {
+		http.Error(response, fmt.Sprintf("could not decode AdmissionReview: %v", err), http.StatusBadRequest)
+		return
+	}
+	if admissionReview.Request == nil {
+		http.Error(response, "AdmissionReview does not contain a request", http.StatusBadRequest)
+		return
+	}
+
+	admissionResponse := review(admissionReview.Request)
+	admissionResponse.UID = admissionReview.Request.UID
+
+	result := admissionv1.AdmissionReview{
+		TypeMeta: admissionReview.TypeMeta,
+		Response: admissionResponse,
+	}
+
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(response).Encode(result)
+}
+
+// isPodRequest returns true if an admission request is about a pod object (rather than a
+// subresource, such as pods/status)
+func isPodRequest(request *admissionv1.AdmissionRequest) bool {
+	podResource := metav1.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	return request.Resource == podResource && request.SubResource == ""
+}
+
+func allowed() *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		Allowed: true,
+	}
+}
+
+func denied(reason metav1.StatusReason, message string, causes []metav1.StatusCause) *admissionv1.AdmissionResponse {
+	status := &metav1.Status{
+		Status:  metav1.StatusFailure,
+		Reason:  reason,
+		Message: message,
+	}
+
+	switch reason {
+	case metav1.StatusReasonBadRequest:
+		status.Code = http.StatusBadRequest
+	case metav1.StatusReasonInternalError:
+		status.Code = http.StatusInternalServerError
+	default:
+		status.Code = http.StatusUnprocessableEntity
+	}
+
+	if len(causes) > 0 {
+		status.Details = &metav1.StatusDetails{
+			Causes: causes,
+		}
+	}
+
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result:  status,
+	}
+}
@@ -0,0 +1,52 @@
+package admission
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Netflix/titus-kube-common/pod"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+type mutatingHandler struct{}
+
+// NewMutatingHandler returns an http.Handler for a mutating admission webhook. It patches
+// pods on creation with the JSON patch from pod.NormalizationPatch.
+func NewMutatingHandler() http.Handler {
+	return &mutatingHandler{}
+}
+
+func (m *mutatingHandler) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	serveAdmissionReview(response, request, m.review)
+}
+
+func (m *mutatingHandler) review(request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if !isPodRequest(request) || request.Operation != admissionv1.Create {
+		return allowed()
+	}
+
+	p := corev1.Pod{}
+	if err := json.Unmarshal(request.Object.Raw, &p); err != nil {
+		return denied(metav1.StatusReasonBadRequest, fmt.Sprintf("could not decode pod: %v", err), nil)
+	}
+
+	patch := pod.NormalizationPatch(&p)
+	if len(patch) == 0 {
+		return allowed()
+	}
+
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return denied(metav1.StatusReasonInternalError, fmt.Sprintf("could not encode patch: %v", err), nil)
+	}
+
+	patchType := admissionv1.PatchTypeJSONPatch
+	return &admissionv1.AdmissionResponse{
+		Allowed:   true,
+		Patch:     patchBytes,
+		PatchType: &patchType,
+	}
+}
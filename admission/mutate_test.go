@@ -0,0 +1,49 @@
+package admission
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestMutatingHandlerPatch(t *testing.T) {
+	review := postReview(t, NewMutatingHandler(), loadFixture(t, "legacy-pod.json"))
+	assert.Equal(t, string(review.Response.UID), "c4a5d2f7-9d55-4b7e-8b1a-0d1c6b3f1e03")
+	assert.Equal(t, review.Response.Allowed, true)
+	assert.Assert(t, review.Response.PatchType != nil)
+	assert.Equal(t, *review.Response.PatchType, admissionv1.PatchTypeJSONPatch)
+
+	patch := []map[string]interface{}{}
+	assert.NilError(t, json.Unmarshal(review.Response.Patch, &patch))
+	assert.DeepEqual(t, []map[string]interface{}{
+		{"op": "add", "path": "/metadata/annotations/network.netflix.com~1security-groups", "value": "sg-1,sg-2"},
+		{"op": "replace", "path": "/metadata/annotations/network.titus.netflix.com~1securityGroups", "value": "sg-1,sg-2"},
+		{"op": "add", "path": "/metadata/annotations/pod.netflix.com~1pod-schema-version", "value": "1"},
+		{"op": "add", "path": "/metadata/annotations/workload.netflix.com~1name", "value": "helloworld"},
+		{"op": "add", "path": "/metadata/annotations/workload.netflix.com~1stack", "value": "teststack"},
+		{"op": "add", "path": "/metadata/labels/v3.job.titus.netflix.com~1job-id", "value": "a318b9eb-50bf-4927-a9eb-b3d5a757f364"},
+		{"op": "add", "path": "/metadata/labels/workload.netflix.com~1name", "value": "helloworld"},
+		{"op": "add", "path": "/metadata/labels/workload.netflix.com~1stack", "value": "teststack"},
+	}, patch)
+}
+
+func TestMutatingHandlerNoPatch(t *testing.T) {
+	review := postReview(t, NewMutatingHandler(), loadFixture(t, "valid-pod.json"))
+	assert.Equal(t, review.Response.Allowed, true)
+	assert.Assert(t, review.Response.Patch == nil)
+	assert.Assert(t, review.Response.PatchType == nil)
+}
+
+func TestMutatingHandlerIgnoresUpdates(t *testing.T) {
+	review := admissionv1.AdmissionReview{}
+	assert.NilError(t, json.Unmarshal(loadFixture(t, "legacy-pod.json"), &review))
+	review.Request.Operation = admissionv1.Update
+	body, err := json.Marshal(review)
+	assert.NilError(t, err)
+
+	result := postReview(t, NewMutatingHandler(), body)
+	assert.Equal(t, result.Response.Allowed, true)
+	assert.Assert(t, result.Response.Patch == nil)
+}
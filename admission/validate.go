@@ -0,0 +1,104 @@
+package admission
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Netflix/titus-kube-common/pod"
+	multierror "github.com/hashicorp/go-multierror"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+type validatingHandler struct{}
+
+// NewValidatingHandler returns an http.Handler for a validating admission webhook. It parses
+// pods with pod.PodToConfig, and denies any pod that fails to parse, listing each error. Pods
+// that parse are also denied if their AWS identifiers aren't valid, or their security context
+// doesn't allow the features they enable (see pod.Config.ValidateAWSIdentifiers and
+// ValidateSecurityContext).
+func NewValidatingHandler() http.Handler {
+	return &validatingHandler{}
+}
+
+func (v *validatingHandler) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	serveAdmissionReview(response, request, v.review)
+}
+
+func (v *validatingHandler) review(request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if !isPodRequest(request) || request.Operation == admissionv1.Delete {
+		return allowed()
+	}
+
+	p := corev1.Pod{}
+	if err := json.Unmarshal(request.Object.Raw, &p); err != nil {
+		return denied(metav1.StatusReasonBadRequest, fmt.Sprintf("could not decode pod: %v", err), nil)
+	}
+
+	conf, err := pod.PodToConfig(&p)
+	if err == nil {
+		// Only check a config that parsed completely, so errors are reported against the right keys
+		var mErr *multierror.Error
+		if aErr := conf.ValidateAWSIdentifiers(); aErr != nil {
+			mErr = multierror.Append(mErr, aErr)
+		}
+		if sErr := conf.ValidateSecurityContext(); sErr != nil {
+			mErr = multierror.Append(mErr, sErr)
+		}
+		err = mErr.ErrorOrNil()
+	}
+	if err == nil {
+		return allowed()
+	}
+
+	errs := []error{err}
+	if mErr, ok := err.(*multierror.Error); ok {
+		errs = mErr.Errors
+	}
+
+	causes := []metav1.StatusCause{}
+	messages := []string{}
+	for _, e := range errs {
+		cause := metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: e.Error(),
+		}
+		var fErr *pod.FieldError
+		if errors.As(e, &fErr) {
+			cause.Field = fieldPath(&p, fErr)
+		}
+		causes = append(causes, cause)
+		messages = append(messages, e.Error())
+	}
+
+	message := fmt.Sprintf("pod has invalid Titus configuration: %s", strings.Join(messages, "; "))
+	return denied(metav1.StatusReasonInvalid, message, causes)
+}
+
+// Return the path of the label, annotation, resource limit or capability an error is about, eg:
+// metadata.annotations[foo]
+func fieldPath(p *corev1.Pod, fErr *pod.FieldError) string {
+	key := fErr.Key
+	if fErr.Container != "" {
+		for i, c := range p.Spec.Containers {
+			if c.Name != fErr.Container {
+				continue
+			}
+			if key == pod.FieldKeyCapabilities {
+				return fmt.Sprintf("spec.containers[%d].%s", i, key)
+			}
+			return fmt.Sprintf("spec.containers[%d].resources.limits[%s]", i, key)
+		}
+	}
+
+	if _, ok := p.Labels[key]; ok {
+		if _, ok := p.Annotations[key]; !ok {
+			return fmt.Sprintf("metadata.labels[%s]", key)
+		}
+	}
+	return fmt.Sprintf("metadata.annotations[%s]", key)
+}
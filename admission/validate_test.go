@@ -0,0 +1,193 @@
+package admission
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Netflix/titus-kube-common/pod"
+	"gotest.tools/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func loadFixture(t *testing.T, name string) []byte {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	assert.NilError(t, err)
+	return data
+}
+
+func postReview(t *testing.T, handler http.Handler, body []byte) *admissionv1.AdmissionReview {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/validate", bytes.NewReader(body)))
+	assert.Equal(t, recorder.Code, 200)
+
+	review := &admissionv1.AdmissionReview{}
+	assert.NilError(t, json.Unmarshal(recorder.Body.Bytes(), review))
+	assert.Equal(t, review.APIVersion, "admission.k8s.io/v1")
+	assert.Equal(t, review.Kind, "AdmissionReview")
+	assert.Assert(t, review.Response != nil)
+	return review
+}
+
+func TestValidatingHandlerAllowed(t *testing.T) {
+	review := postReview(t, NewValidatingHandler(), loadFixture(t, "valid-pod.json"))
+	assert.Equal(t, string(review.Response.UID), "c4a5d2f7-9d55-4b7e-8b1a-0d1c6b3f1e01")
+	assert.Equal(t, review.Response.Allowed, true)
+	assert.Assert(t, review.Response.Result == nil)
+}
+
+func TestValidatingHandlerDenied(t *testing.T) {
+	review := postReview(t, NewValidatingHandler(), loadFixture(t, "invalid-pod.json"))
+	assert.Equal(t, string(review.Response.UID), "c4a5d2f7-9d55-4b7e-8b1a-0d1c6b3f1e02")
+	assert.Equal(t, review.Response.Allowed, false)
+
+	result := review.Response.Result
+	assert.Equal(t, result.Code, int32(http.StatusUnprocessableEntity))
+	assert.Equal(t, result.Reason, metav1.StatusReasonInvalid)
+	assert.Assert(t, result.Details != nil)
+
+	messages := []string{}
+	fields := []string{}
+	for _, c := range result.Details.Causes {
+		assert.Equal(t, c.Type, metav1.CauseTypeFieldValueInvalid)
+		messages = append(messages, c.Message)
+		fields = append(fields, c.Field)
+	}
+	assert.DeepEqual(t, []string{
+		"annotation is not a valid boolean value: pod.netflix.com/cpu-bursting-enabled",
+		"annotation is not a valid scheduler policy: pod.netflix.com/sched-policy",
+		"error parsing service image annotation: service.netflix.com/servicemesh.v2.image: image does not have a digest or tag",
+	}, messages)
+	assert.DeepEqual(t, []string{
+		"metadata.annotations[pod.netflix.com/cpu-bursting-enabled]",
+		"metadata.annotations[pod.netflix.com/sched-policy]",
+		"metadata.annotations[service.netflix.com/servicemesh.v2.image]",
+	}, fields)
+	assert.Assert(t, strings.HasPrefix(result.Message, "pod has invalid Titus configuration: "))
+}
+
+// Load an AdmissionReview fixture, and modify the pod in it
+func modifyFixturePod(t *testing.T, name string, modify func(p *corev1.Pod)) []byte {
+	review := admissionv1.AdmissionReview{}
+	assert.NilError(t, json.Unmarshal(loadFixture(t, name), &review))
+	p := corev1.Pod{}
+	assert.NilError(t, json.Unmarshal(review.Request.Object.Raw, &p))
+	modify(&p)
+
+	var err error
+	review.Request.Object.Raw, err = json.Marshal(p)
+	assert.NilError(t, err)
+	body, err := json.Marshal(review)
+	assert.NilError(t, err)
+	return body
+}
+
+func TestValidatingHandlerDeniesAWSIdentifiers(t *testing.T) {
+	body := modifyFixturePod(t, "valid-pod.json", func(p *corev1.Pod) {
+		p.Annotations[pod.AnnotationKeyNetworkSubnetIDs] = "subnet-1"
+	})
+
+	result := postReview(t, NewValidatingHandler(), body).Response
+	assert.Equal(t, result.Allowed, false)
+	assert.Equal(t, result.Result.Reason, metav1.StatusReasonInvalid)
+	assert.Equal(t, len(result.Result.Details.Causes), 1)
+	cause := result.Result.Details.Causes[0]
+	assert.Equal(t, cause.Field, "metadata.annotations["+pod.AnnotationKeyNetworkSubnetIDs+"]")
+	assert.Equal(t, cause.Message, `annotation is not a valid list of subnets: network.netflix.com/subnet-ids: subnet ID is not valid: "subnet-1"`)
+}
+
+func TestValidatingHandlerDeniesSecurityContext(t *testing.T) {
+	body := modifyFixturePod(t, "valid-pod.json", func(p *corev1.Pod) {
+		p.Annotations[pod.AnnotationKeyPodFuseEnabled] = "true"
+		p.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{
+			Capabilities: &corev1.Capabilities{
+				Add:  []corev1.Capability{"NET_RAW"},
+				Drop: []corev1.Capability{"NET_RAW"},
+			},
+		}
+	})
+
+	result := postReview(t, NewValidatingHandler(), body).Response
+	assert.Equal(t, result.Allowed, false)
+
+	fields := []string{}
+	for _, c := range result.Result.Details.Causes {
+		fields = append(fields, c.Field)
+	}
+	assert.DeepEqual(t, []string{
+		"spec.containers[0].securityContext.capabilities",
+		"metadata.annotations[" + pod.AnnotationKeyPodFuseEnabled + "]",
+	}, fields)
+}
+
+func TestValidatingHandlerIgnoresDeletes(t *testing.T) {
+	review := admissionv1.AdmissionReview{}
+	assert.NilError(t, json.Unmarshal(loadFixture(t, "invalid-pod.json"), &review))
+	review.Request.Operation = admissionv1.Delete
+	body, err := json.Marshal(review)
+	assert.NilError(t, err)
+
+	result := postReview(t, NewValidatingHandler(), body)
+	assert.Equal(t, result.Response.Allowed, true)
+}
+
+func TestValidatingHandlerBadRequests(t *testing.T) {
+	handler := NewValidatingHandler()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/validate", nil))
+	assert.Equal(t, recorder.Code, http.StatusMethodNotAllowed)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/validate", bytes.NewReader([]byte("not json"))))
+	assert.Equal(t, recorder.Code, http.StatusBadRequest)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/validate", bytes.NewReader([]byte(`{"kind":"AdmissionReview"}`))))
+	assert.Equal(t, recorder.Code, http.StatusBadRequest)
+}
+
+func TestValidatingHandlerRequestTooLarge(t *testing.T) {
+	handler := NewValidatingHandler()
+
+	// A body just over the limit is rejected as too large, rather than failing to decode
+	body := bytes.Repeat([]byte(" "), maxRequestSize+1)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/validate", bytes.NewReader(body)))
+	assert.Equal(t, recorder.Code, http.StatusRequestEntityTooLarge)
+
+	// A body at the limit is read in full
+	body = append(loadFixture(t, "valid-pod.json"), bytes.Repeat([]byte(" "), maxRequestSize)...)[:maxRequestSize]
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/validate", bytes.NewReader(body)))
+	assert.Equal(t, recorder.Code, http.StatusOK)
+}
+
+func TestFieldPath(t *testing.T) {
+	p := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{"both": "a"},
+			Labels:      map[string]string{"both": "l", "label": "l"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "sidecar"}, {Name: "main"}},
+		},
+	}
+
+	assert.Equal(t, fieldPath(p, &pod.FieldError{Key: "label"}), "metadata.labels[label]")
+	assert.Equal(t, fieldPath(p, &pod.FieldError{Key: "both"}), "metadata.annotations[both]")
+	assert.Equal(t, fieldPath(p, &pod.FieldError{Key: "missing"}), "metadata.annotations[missing]")
+	assert.Equal(t, fieldPath(p, &pod.FieldError{Key: "titus/gpu", ExpectedType: pod.KeyTypeQuantity, Container: "main"}),
+		"spec.containers[1].resources.limits[titus/gpu]")
+	assert.Equal(t, fieldPath(p, &pod.FieldError{Key: "titus/network", Container: "main"}),
+		"spec.containers[1].resources.limits[titus/network]")
+	assert.Equal(t, fieldPath(p, &pod.FieldError{Key: pod.FieldKeyCapabilities, Value: "NET_RAW", Container: "main"}),
+		"spec.containers[1].securityContext.capabilities")
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	_ "github.com/Netflix/titus-kube-common/admission"
 	_ "github.com/Netflix/titus-kube-common/node"
 	_ "github.com/Netflix/titus-kube-common/pod"
 	_ "github.com/Netflix/titus-kube-common/resource"
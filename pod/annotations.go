@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -184,336 +185,165 @@ func parseAnnotations(pod *corev1.Pod, pConf *Config) error {
 		return errors.New("no containers found in pod")
 	}
 
-	boolAnnotations := []struct {
-		key   string
-		field **bool
-	}{
-		{
-			key:   AnnotationKeyLogKeepLocalFile,
-			field: &pConf.LogKeepLocalFile,
-		},
-		{
-			key:   AnnotationKeyNetworkAssignIPv6Address,
-			field: &pConf.AssignIPv6Address,
-		},
-		{
-			key:   AnnotationKeyNetworkBurstingEnabled,
-			field: &pConf.NetworkBurstingEnabled,
-		},
-		{
-			key:   AnnotationKeyNetworkJumboFramesEnabled,
-			field: &pConf.JumboFramesEnabled,
-		},
-		{
-			key:   AnnotationKeyPodCPUBurstingEnabled,
-			field: &pConf.CPUBurstingEnabled,
-		},
-		{
-			key:   AnnotationKeyPodFuseEnabled,
-			field: &pConf.FuseEnabled,
-		},
-		{
-			key:   AnnotationKeyPodKvmEnabled,
-			field: &pConf.KvmEnabled,
-		},
-		{
-			key:   AnnotationKeyPodSeccompAgentNetEnabled,
-			field: &pConf.SeccompAgentNetEnabled,
-		},
-		{
-			key:   AnnotationKeyPodSeccompAgentPerfEnabled,
-			field: &pConf.SeccompAgentPerfEnabled,
-		},
-		{
-			key:   AnnotationKeyPodTitusEntrypointShellSplitting,
-			field: &pConf.EntrypointShellSplitting,
-		},
-	}
+	var err *multierror.Error
 
-	durationAnnotations := []struct {
-		key   string
-		field **time.Duration
-	}{
-		{
-			key:   AnnotationKeyLogStdioCheckInterval,
-			field: &pConf.LogStdioCheckInterval,
-		},
-		{
-			key:   AnnotationKeyLogUploadCheckInterval,
-			field: &pConf.LogUploadCheckInterval,
-		},
-		{
-			key:   AnnotationKeyLogUploadThresholdTime,
-			field: &pConf.LogUploadThresholdTime,
-		},
+	if appArmorVal, ok := annotations[AnnotationKeyPrefixAppArmor+"/"+userCtr.Name]; ok {
+		pConf.AppArmorProfile = &appArmorVal
 	}
 
-	resourceAnnotations := []struct {
-		key   string
-		field **resource.Quantity
-	}{
-		{
-			key:   AnnotationKeyEgressBandwidth,
-			field: &pConf.EgressBandwidth,
-		},
-		{
-			key:   AnnotationKeyIngressBandwidth,
-			field: &pConf.IngressBandwidth,
-		},
+	for _, spec := range annotationCatalog {
+		if spec.field == nil {
+			continue
+		}
+		val, ok := annotations[spec.Key]
+		if !ok {
+			continue
+		}
+		if pErr := parseAnnotationValue(spec, val, spec.field(pConf)); pErr != nil {
+			err = multierror.Append(err, pErr)
+		}
 	}
 
-	stringAnnotations := []struct {
-		key   string
-		field **string
-	}{
-		{
-			key:   AnnotationKeyPrefixAppArmor + "/" + userCtr.Name,
-			field: &pConf.AppArmorProfile,
-		},
-		{
-			key:   AnnotationKeyWorkloadDetail,
-			field: &pConf.WorkloadDetail,
-		},
-		{
-			key:   AnnotationKeyWorkloadName,
-			field: &pConf.WorkloadName,
-		},
-		{
-			key:   AnnotationKeyWorkloadOwnerEmail,
-			field: &pConf.WorkloadOwnerEmail,
-		},
-		{
-			key:   AnnotationKeyWorkloadSequence,
-			field: &pConf.WorkloadSequence,
-		},
-		{
-			key:   AnnotationKeyWorkloadStack,
-			field: &pConf.WorkloadStack,
-		},
-		{
-			key:   AnnotationKeyIAMRole,
-			field: &pConf.IAMRole,
-		},
-		{
-			key:   AnnotationKeyJobDescriptor,
-			field: &pConf.JobDescriptor,
-		},
-		{
-			key:   AnnotationKeyJobID,
-			field: &pConf.JobID,
-		},
-		{
-			key:   AnnotationKeyJobType,
-			field: &pConf.JobType,
-		},
-		{
-			key:   AnnotationKeyLogS3BucketName,
-			field: &pConf.LogS3BucketName,
-		},
-		{
-			key:   AnnotationKeyLogS3PathPrefix,
-			field: &pConf.LogS3PathPrefix,
-		},
-		{
-			key:   AnnotationKeyLogS3WriterIAMRole,
-			field: &pConf.LogS3WriterIAMRole,
-		},
-		{
-			key:   AnnotationKeyNetworkAccountID,
-			field: &pConf.AccountID,
-		},
-		{
-			key:   AnnotationKeyNetworkElasticIPPool,
-			field: &pConf.ElasticIPPool,
-		},
-		{
-			key:   AnnotationKeyNetworkElasticIPs,
-			field: &pConf.ElasticIPs,
-		},
-		{
-			key:   AnnotationKeyNetworkIMDSRequireToken,
-			field: &pConf.IMDSRequireToken,
-		},
-		{
-			key:   AnnotationKeyNetworkStaticIPAllocationUUID,
-			field: &pConf.StaticIPAllocationUUID,
-		},
-		{
-			key:   AnnotationKeyPodTitusContainerInfo,
-			field: &pConf.ContainerInfo,
-		},
-		{
-			key:   AnnotationKeyPodHostnameStyle,
-			field: &pConf.HostnameStyle,
-		},
-		{
-			key:   AnnotationKeyPodSchedPolicy,
-			field: &pConf.SchedPolicy,
-		},
-		{
-			key:   AnnotationKeySecurityWorkloadMetadata,
-			field: &pConf.WorkloadMetadata,
-		},
-		{
-			key:   AnnotationKeySecurityWorkloadMetadataSig,
-			field: &pConf.WorkloadMetadataSig,
-		},
-		{
-			key:   AnnotationKeyNetworkMode,
-			field: &pConf.NetworkMode,
-		},
+	if oErr := parseOpportunisticAnnotations(annotations, pConf); oErr != nil {
+		err = multierror.Append(err, oErr)
 	}
 
-	uint32Annotations := []struct {
-		key   string
-		field **uint32
-	}{
-		{
-			key:   AnnotationKeyPodSchemaVersion,
-			field: &pConf.PodSchemaVersion,
-		},
+	if pErr := parsePredictionAnnotations(annotations, pConf); pErr != nil {
+		err = multierror.Append(err, pErr)
 	}
 
-	var err *multierror.Error
-
-	for _, an := range stringAnnotations {
-		val, ok := annotations[an.key]
-		if ok {
-			*an.field = &val
-		}
+	if eErr := parseEBSAnnotations(pod, userCtr, pConf); eErr != nil {
+		err = multierror.Append(err, eErr)
 	}
 
-	if hostnameStyle, ok := annotations[AnnotationKeyPodHostnameStyle]; ok {
-		if hostnameStyle != "ec2" && hostnameStyle != "" {
-			err = multierror.Append(err, fmt.Errorf("annotation is not a valid hostname style: %s", AnnotationKeyPodHostnameStyle))
-		}
+	if sErr := parseServiceAnnotations(annotations, pConf); sErr != nil {
+		err = multierror.Append(err, sErr)
 	}
 
-	for _, an := range boolAnnotations {
-		val, ok := annotations[an.key]
-		if ok {
-			boolVal, pErr := strconv.ParseBool(val)
-			if pErr == nil {
-				*an.field = &boolVal
-			} else {
-				err = multierror.Append(err, fmt.Errorf("annotation is not a valid boolean value: %s", an.key))
-			}
-		}
-	}
+	return err.ErrorOrNil()
+}
 
-	for _, an := range uint32Annotations {
-		val, ok := annotations[an.key]
-		if ok {
-			parsedVal, pErr := strconv.ParseUint(val, 10, 32)
-			if pErr == nil {
-				parsedUint32 := uint32(parsedVal)
-				*an.field = &parsedUint32
-			} else {
-				err = multierror.Append(err, fmt.Errorf("annotation is not a valid uint32 value: %s", an.key))
-			}
+// Parse an annotation value into a Config field, based on the type of the field
+func parseAnnotationValue(spec keySpec, val string, field interface{}) error {
+	switch f := field.(type) {
+	case **string:
+		*f = &val
+	case **bool:
+		boolVal, pErr := strconv.ParseBool(val)
+		if pErr != nil {
+			return newFieldError(spec.Key, val, spec.Type, "annotation is not a valid boolean value")
 		}
-	}
-
-	val, ok := annotations[AnnotationKeyJobAcceptedTimestampMs]
-	if ok {
+		*f = &boolVal
+	case **uint32:
+		parsedVal, pErr := strconv.ParseUint(val, 10, 32)
+		if pErr != nil {
+			return newFieldError(spec.Key, val, spec.Type, "annotation is not a valid uint32 value")
+		}
+		parsedUint32 := uint32(parsedVal)
+		*f = &parsedUint32
+	case **uint64:
 		parsedVal, pErr := strconv.ParseUint(val, 10, 64)
-		if pErr == nil {
-			parsedUint64 := uint64(parsedVal)
-			pConf.JobAcceptedTimestampMs = &parsedUint64
-		} else {
-			err = multierror.Append(err, fmt.Errorf("annotation is not a valid uint64 value: %s", AnnotationKeyJobAcceptedTimestampMs))
+		if pErr != nil {
+			return newFieldError(spec.Key, val, spec.Type, "annotation is not a valid uint64 value")
 		}
-	}
-
-	val, ok = annotations[AnnotationKeyPodOomScoreAdj]
-	if ok {
+		*f = &parsedVal
+	case **int32:
 		parsedVal, pErr := strconv.ParseInt(val, 10, 32)
-		if pErr == nil {
-			parsedInt32 := int32(parsedVal)
-			pConf.OomScoreAdj = &parsedInt32
-		} else {
-			err = multierror.Append(err, fmt.Errorf("annotation is not a valid int32 value: %s", AnnotationKeyPodOomScoreAdj))
+		if pErr != nil {
+			return newFieldError(spec.Key, val, spec.Type, "annotation is not a valid int32 value")
 		}
-	}
-
-	for _, an := range resourceAnnotations {
-		val, ok := annotations[an.key]
-		if ok {
-			resVal, pErr := resource.ParseQuantity(val)
-			if pErr == nil {
-				*an.field = &resVal
-			} else {
-				err = multierror.Append(err, fmt.Errorf("annotation is not a valid resource value: %s", an.key))
-			}
+		parsedInt32 := int32(parsedVal)
+		*f = &parsedInt32
+	case **resource.Quantity:
+		resVal, pErr := resource.ParseQuantity(val)
+		if pErr != nil {
+			return newFieldError(spec.Key, val, spec.Type, "annotation is not a valid resource value")
 		}
-	}
-
-	for _, an := range durationAnnotations {
-		val, ok := annotations[an.key]
-		if ok {
-			durVal, pErr := time.ParseDuration(val)
-			if pErr == nil {
-				*an.field = &durVal
-			} else {
-				err = multierror.Append(err, fmt.Errorf("annotation is not a valid duration value: %s", an.key))
-			}
+		*f = &resVal
+	case **time.Duration:
+		durVal, pErr := time.ParseDuration(val)
+		if pErr != nil {
+			return newFieldError(spec.Key, val, spec.Type, "annotation is not a valid duration value")
 		}
-	}
-
-	if uploadRegexpVal, ok := annotations[AnnotationKeyLogUploadRegexp]; ok {
-		uploadRegexp, pErr := regexp.Compile(uploadRegexpVal)
-		if pErr == nil {
-			pConf.LogUploadRegExp = uploadRegexp
-		} else {
-			err = multierror.Append(err, fmt.Errorf("annotation is not a valid regexp value: %s: %w", AnnotationKeyLogUploadRegexp, pErr))
+		*f = &durVal
+	case **regexp.Regexp:
+		regexpVal, pErr := regexp.Compile(val)
+		if pErr != nil {
+			fErr := newFieldError(spec.Key, val, spec.Type, "annotation is not a valid regexp value")
+			fErr.Err = pErr
+			return fErr
 		}
+		*f = regexpVal
+	case **[]string:
+		list := splitList(val)
+		*f = &list
+	case *[]string:
+		*f = splitList(val)
+	default:
+		return newFieldError(spec.Key, val, spec.Type, fmt.Sprintf("annotation has an unsupported field type %T", field))
 	}
 
-	if sgVal, ok := annotations[AnnotationKeyNetworkSecurityGroups]; ok {
-		sgsSplit := strings.Split(strings.TrimSpace(sgVal), ",")
-		sgIDs := []string{}
-		for _, sg := range sgsSplit {
-			sgIDs = append(sgIDs, strings.TrimSpace(sg))
-		}
-		pConf.SecurityGroupIDs = &sgIDs
+	if len(spec.AllowedValues) > 0 && !containsString(spec.AllowedValues, val) {
+		return newFieldError(spec.Key, val, spec.Type, "annotation is not a valid "+spec.allowedDesc)
 	}
 
-	if subVal, ok := annotations[AnnotationKeyNetworkSubnetIDs]; ok {
-		subsSplit := strings.Split(strings.TrimSpace(subVal), ",")
-		subIDs := []string{}
-		for _, sub := range subsSplit {
-			subIDs = append(subIDs, strings.TrimSpace(sub))
-		}
-		pConf.SubnetIDs = &subIDs
+	return nil
+}
+
+// Split a comma-separated annotation value, trimming whitespace around each element
+func splitList(val string) []string {
+	split := strings.Split(strings.TrimSpace(val), ",")
+	list := []string{}
+	for _, v := range split {
+		list = append(list, strings.TrimSpace(v))
 	}
+	return list
+}
 
-	if envVal, ok := annotations[AnnotationKeyPodTitusSystemEnvVarNames]; ok {
-		envsSplit := strings.Split(strings.TrimSpace(envVal), ",")
-		for _, env := range envsSplit {
-			pConf.SystemEnvVarNames = append(pConf.SystemEnvVarNames, strings.TrimSpace(env))
+// Parse the "opportunistic.scheduler.titus.netflix.com/*" annotations
+func parseOpportunisticAnnotations(annotations map[string]string, pConf *Config) error {
+	var err *multierror.Error
+	opportunistic := OpportunisticResources{}
+
+	cpuVal, cpuOk := annotations[AnnotationKeyOpportunisticCPU]
+	if cpuOk {
+		cpu, pErr := resource.ParseQuantity(cpuVal)
+		switch {
+		case pErr != nil:
+			err = multierror.Append(err, newFieldError(AnnotationKeyOpportunisticCPU, cpuVal, KeyTypeQuantity, "annotation is not a valid resource value"))
+		case cpu.Sign() < 0 || cpu.MilliValue()%1000 != 0:
+			err = multierror.Append(err, newFieldError(AnnotationKeyOpportunisticCPU, cpuVal, KeyTypeQuantity, "annotation is not a valid opportunistic CPU count"))
+		default:
+			opportunistic.CPU = &cpu
 		}
 	}
 
-	if pConf.SchedPolicy != nil && *pConf.SchedPolicy != "batch" && *pConf.SchedPolicy != "idle" {
-		err = multierror.Append(err, fmt.Errorf("annotation is not a valid scheduler policy: %s", AnnotationKeyPodSchedPolicy))
+	idVal, idOk := annotations[AnnotationKeyOpportunisticResourceID]
+	if idOk {
+		opportunistic.ResourceID = &idVal
 	}
 
-	if sErr := parseServiceAnnotations(annotations, pConf); sErr != nil {
-		err = multierror.Append(err, sErr)
+	if cpuOk && !idOk {
+		err = multierror.Append(err, newFieldError(AnnotationKeyOpportunisticResourceID, "", KeyTypeString, "annotation must be set when "+AnnotationKeyOpportunisticCPU+" is set"))
 	}
 
-	if err == nil {
-		return nil
+	if opportunistic.CPU != nil || opportunistic.ResourceID != nil {
+		pConf.Opportunistic = &opportunistic
 	}
+
 	return err.ErrorOrNil()
 }
 
-// Parse the "service.netflix.com/svc.v0.name" annotations
+// Parse the "service.netflix.com/svc.v0.name" annotations. Parameters other than "enabled" and
+// "image" are kept in the sidecar's Params. Sidecars are returned sorted by name and version.
 func parseServiceAnnotations(annotations map[string]string, pConf *Config) error {
 	var err *multierror.Error
 	sidecars := map[string]Sidecar{}
+	// The version string each sidecar was first seen with, to catch duplicates like v2 and v02
+	versionStrs := map[string]string{}
 
-	for k, v := range annotations {
+	for _, k := range sortedKeys(annotations) {
+		v := annotations[k]
 		if !strings.HasPrefix(k, AnnotationKeyServicePrefix) {
 			continue
 		}
@@ -521,54 +351,92 @@ func parseServiceAnnotations(annotations map[string]string, pConf *Config) error
 		// name, version, value, eg: servicemesh.v2.image
 		splitOut := strings.Split(strings.TrimPrefix(k, AnnotationKeyServicePrefix+"/"), ".")
 		if len(splitOut) != 3 {
-			err = multierror.Append(err, fmt.Errorf("annotation has an incorrect number of service configuration parameters: %s", k))
+			err = multierror.Append(err, newFieldError(k, v, KeyTypeStructured, "annotation has an incorrect number of service configuration parameters"))
 			continue
 		}
 		name := splitOut[0]
 		version := splitOut[1]
 		param := splitOut[2]
 
-		sc, ok := sidecars[name+"."+version]
-		if !ok {
-			vInt, vErr := strconv.Atoi(strings.TrimPrefix(version, "v"))
-			if vErr != nil {
-				err = multierror.Append(err, fmt.Errorf("annotation has an incorrect service version number: %s", k))
-				continue
-			}
+		vInt, vErr := strconv.Atoi(strings.TrimPrefix(version, "v"))
+		if vErr != nil {
+			err = multierror.Append(err, newFieldError(k, v, KeyTypeStructured, "annotation has an incorrect service version number"))
+			continue
+		}
+
+		scKey := fmt.Sprintf("%s.v%d", name, vInt)
+		if firstVersion, ok := versionStrs[scKey]; ok && firstVersion != version {
+			err = multierror.Append(err, newFieldError(k, v, KeyTypeStructured, "annotation duplicates service version "+firstVersion))
+			continue
+		}
+		versionStrs[scKey] = version
 
+		sc, ok := sidecars[scKey]
+		if !ok {
 			sc = Sidecar{
 				Name:    name,
 				Version: vInt,
 			}
 		}
 
-		if param == "enabled" {
+		switch param {
+		case "enabled":
 			boolVal, pErr := strconv.ParseBool(v)
 			if pErr != nil {
-				err = multierror.Append(err, fmt.Errorf("annotation has an incorrect service enabled boolean value: %s", k))
+				err = multierror.Append(err, newFieldError(k, v, KeyTypeBool, "annotation has an incorrect service enabled boolean value"))
 				continue
 			}
 			sc.Enabled = boolVal
-		}
-
-		if param == "image" {
+		case "image":
 			if iErr := validateImage(v); iErr != nil {
-				err = multierror.Append(err, fmt.Errorf("error parsing service image annotation: %s: %w", k, iErr))
+				fErr := newFieldError(k, v, KeyTypeString, "error parsing service image annotation")
+				fErr.Err = iErr
+				err = multierror.Append(err, fErr)
 				continue
 			}
 			sc.Image = v
+		default:
+			if sc.Params == nil {
+				sc.Params = map[string]string{}
+			}
+			sc.Params[param] = v
 		}
 
-		sidecars[name+"."+version] = sc
+		sidecars[scKey] = sc
 	}
 
 	for _, sc := range sidecars {
 		pConf.Sidecars = append(pConf.Sidecars, sc)
 	}
 
-	if err == nil {
-		return nil
+	sort.Slice(pConf.Sidecars, func(i, j int) bool {
+		if pConf.Sidecars[i].Name != pConf.Sidecars[j].Name {
+			return pConf.Sidecars[i].Name < pConf.Sidecars[j].Name
+		}
+		return pConf.Sidecars[i].Version < pConf.Sidecars[j].Version
+	})
+
+	// Only one version of each service can run, so at most one can be enabled
+	enabledVersions := map[string]string{}
+	for _, sc := range pConf.Sidecars {
+		if !sc.Enabled {
+			continue
+		}
+
+		version := versionStrs[fmt.Sprintf("%s.v%d", sc.Name, sc.Version)]
+		keyPrefix := fmt.Sprintf("%s/%s.%s.", AnnotationKeyServicePrefix, sc.Name, version)
+		if _, ok := annotations[keyPrefix+"image"]; !ok {
+			err = multierror.Append(err, newFieldError(keyPrefix+"image", "", KeyTypeString, "annotation must be set when the service is enabled"))
+		}
+
+		if firstVersion, ok := enabledVersions[sc.Name]; ok {
+			err = multierror.Append(err, newFieldError(keyPrefix+"enabled", "true", KeyTypeBool,
+				"annotation enables a second version of the service, after "+firstVersion))
+			continue
+		}
+		enabledVersions[sc.Name] = version
 	}
+
 	return err.ErrorOrNil()
 }
 
@@ -583,7 +451,7 @@ func PodSchemaVersion(pod *corev1.Pod) (uint32, error) {
 
 	parsedVal, err := strconv.ParseUint(val, 10, 32)
 	if err != nil {
-		return defaultVal, fmt.Errorf("annotation is not a valid uint32 value: %s", AnnotationKeyPodSchemaVersion)
+		return defaultVal, newFieldError(AnnotationKeyPodSchemaVersion, val, KeyTypeInteger, "annotation is not a valid uint32 value")
 	}
 
 	return uint32(parsedVal), nil
@@ -0,0 +1,311 @@
+package pod
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	resourceCommon "github.com/Netflix/titus-kube-common/resource"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ApplyConfig writes the values in a Config back onto a pod, as annotations, labels and
+// fields of the workload container. It is the inverse of PodToConfig: unset (nil) fields
+// are left untouched on the pod, so PodToConfig(ApplyConfig(conf)) returns the same Config,
+// with two exceptions: a false TTYEnabled reads back as nil, since a container's TTY field
+// can't tell false apart from unset, and empty lists aren't written, so read back as nil.
+func ApplyConfig(pod *corev1.Pod, conf *Config) error {
+	userCtr := GetUserContainer(pod)
+	if userCtr == nil {
+		return errors.New("no containers found in pod")
+	}
+
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	for k, v := range ConfigToAnnotations(conf) {
+		pod.Annotations[k] = v
+	}
+
+	// The AppArmor annotation is keyed on the container name, so it can't be
+	// generated without the pod
+	if conf.AppArmorProfile != nil {
+		pod.Annotations[AnnotationKeyPrefixAppArmor+"/"+userCtr.Name] = *conf.AppArmorProfile
+	}
+
+	if pod.Labels == nil {
+		pod.Labels = map[string]string{}
+	}
+	for k, v := range ConfigToLabels(conf) {
+		pod.Labels[k] = v
+	}
+
+	return applyPodFields(pod, conf)
+}
+
+// ConfigToAnnotations returns the pod annotations that represent the values in a Config.
+// Container-specific annotations (such as the AppArmor profile) are not included, since
+// they depend on the container name: use ApplyConfig to set those.
+func ConfigToAnnotations(conf *Config) map[string]string {
+	annotations := map[string]string{}
+
+	for _, spec := range annotationCatalog {
+		if spec.field == nil {
+			continue
+		}
+		if val, ok := formatAnnotationValue(spec.field(conf)); ok {
+			annotations[spec.Key] = val
+		}
+	}
+
+	if conf.Opportunistic != nil {
+		if conf.Opportunistic.CPU != nil {
+			annotations[AnnotationKeyOpportunisticCPU] = conf.Opportunistic.CPU.String()
+		}
+		if conf.Opportunistic.ResourceID != nil {
+			annotations[AnnotationKeyOpportunisticResourceID] = *conf.Opportunistic.ResourceID
+		}
+	}
+
+	for k, v := range ebsVolumeToAnnotations(conf.EBSVolume) {
+		annotations[k] = v
+	}
+
+	for k, v := range predictionToAnnotations(conf.RuntimePrediction) {
+		annotations[k] = v
+	}
+
+	for k, v := range sidecarsToAnnotations(conf.Sidecars) {
+		annotations[k] = v
+	}
+
+	return annotations
+}
+
+// Format a Config field as an annotation value, based on the type of the field. Returns false
+// if the field is unset.
+func formatAnnotationValue(field interface{}) (string, bool) {
+	switch f := field.(type) {
+	case **string:
+		if *f != nil {
+			return **f, true
+		}
+	case **bool:
+		if *f != nil {
+			return strconv.FormatBool(**f), true
+		}
+	case **uint32:
+		if *f != nil {
+			return strconv.FormatUint(uint64(**f), 10), true
+		}
+	case **uint64:
+		if *f != nil {
+			return strconv.FormatUint(**f, 10), true
+		}
+	case **int32:
+		if *f != nil {
+			return strconv.FormatInt(int64(**f), 10), true
+		}
+	case **resource.Quantity:
+		if *f != nil {
+			return (*f).String(), true
+		}
+	case **time.Duration:
+		if *f != nil {
+			return (*f).String(), true
+		}
+	case **regexp.Regexp:
+		if *f != nil {
+			return (*f).String(), true
+		}
+	// An empty list can't be written, since an empty annotation is parsed as a list containing
+	// one empty string
+	case **[]string:
+		if *f != nil && len(**f) > 0 {
+			return strings.Join(**f, ","), true
+		}
+	case *[]string:
+		if len(*f) > 0 {
+			return strings.Join(*f, ","), true
+		}
+	}
+
+	return "", false
+}
+
+// Generate the "service.netflix.com/svc.v0.name" annotations
+func sidecarsToAnnotations(sidecars []Sidecar) map[string]string {
+	annotations := map[string]string{}
+
+	for _, sc := range sidecars {
+		prefix := fmt.Sprintf("%s/%s.v%d.", AnnotationKeyServicePrefix, sc.Name, sc.Version)
+		annotations[prefix+"enabled"] = strconv.FormatBool(sc.Enabled)
+		if sc.Image != "" {
+			annotations[prefix+"image"] = sc.Image
+		}
+		for param, val := range sc.Params {
+			annotations[prefix+param] = val
+		}
+	}
+
+	return annotations
+}
+
+// ConfigToLabels returns the pod labels that represent the values in a Config. This includes
+// labels that duplicate annotations (such as the workload name), which PodToConfig doesn't read.
+func ConfigToLabels(conf *Config) map[string]string {
+	labels := map[string]string{}
+
+	stringLabels := []struct {
+		key   string
+		field *string
+	}{
+		{
+			key:   LabelKeyCapacityGroup,
+			field: conf.CapacityGroup,
+		},
+		{
+			key:   LabelKeyJobId,
+			field: conf.JobID,
+		},
+		{
+			key:   LabelKeyTaskId,
+			field: conf.TaskID,
+		},
+		{
+			key:   LabelKeyWorkloadName,
+			field: conf.WorkloadName,
+		},
+		{
+			key:   LabelKeyWorkloadStack,
+			field: conf.WorkloadStack,
+		},
+		{
+			key:   LabelKeyWorkloadDetail,
+			field: conf.WorkloadDetail,
+		},
+		{
+			key:   LabelKeyWorkloadSequence,
+			field: conf.WorkloadSequence,
+		},
+	}
+
+	for _, l := range stringLabels {
+		if l.field != nil {
+			labels[l.key] = *l.field
+		}
+	}
+
+	if conf.BytesEnabled != nil {
+		labels[LabelKeyByteUnitsEnabled] = strconv.FormatBool(*conf.BytesEnabled)
+	}
+
+	return labels
+}
+
+func applyPodFields(pod *corev1.Pod, pConf *Config) error {
+	workloadContainer := getWorkloadContainer(pod, pConf)
+	if workloadContainer == nil {
+		return errors.New("could not find workload container in pod")
+	}
+
+	resources := []struct {
+		name  corev1.ResourceName
+		field *resource.Quantity
+	}{
+		{
+			name:  corev1.ResourceCPU,
+			field: pConf.ResourceCPU,
+		},
+		{
+			name:  corev1.ResourceEphemeralStorage,
+			field: pConf.ResourceDisk,
+		},
+		{
+			name:  resourceCommon.ResourceNameGpu,
+			field: pConf.ResourceGPU,
+		},
+		{
+			name:  corev1.ResourceMemory,
+			field: pConf.ResourceMemory,
+		},
+		{
+			name:  resourceCommon.ResourceNameNetwork,
+			field: pConf.ResourceNetwork,
+		},
+	}
+
+	for _, res := range resources {
+		if res.field == nil {
+			continue
+		}
+		if workloadContainer.Resources.Limits == nil {
+			workloadContainer.Resources.Limits = corev1.ResourceList{}
+		}
+		limits := workloadContainer.Resources.Limits
+		limits[res.name] = res.field.DeepCopy()
+		// Drop any legacy names for the resource, and update any other names that are set, so
+		// they can't conflict with the new value
+		for _, alias := range resourceCommon.Aliases[string(res.name)][1:] {
+			aliasName := corev1.ResourceName(alias)
+			if containsString(resourceCommon.LegacyNames[string(res.name)], alias) {
+				delete(limits, aliasName)
+			} else if _, ok := limits[aliasName]; ok {
+				limits[aliasName] = res.field.DeepCopy()
+			}
+		}
+		applyResourceRequests(workloadContainer.Resources.Requests, res.name, *res.field)
+	}
+
+	if pConf.TTYEnabled != nil {
+		workloadContainer.TTY = *pConf.TTYEnabled
+	}
+
+	applySecurityContext(pod, workloadContainer, pConf)
+	return nil
+}
+
+// Keep a container's requests for a resource valid after its limit changes. The API server
+// rejects requests above the limit, and requests for extended resources (such as titus/gpu) that
+// aren't equal to the limit. Requests under a legacy name are moved to the canonical name.
+func applyResourceRequests(requests corev1.ResourceList, name corev1.ResourceName, limit resource.Quantity) {
+	if requests == nil {
+		return
+	}
+
+	for _, legacy := range resourceCommon.LegacyNames[string(name)] {
+		legacyName := corev1.ResourceName(legacy)
+		req, ok := requests[legacyName]
+		if !ok {
+			continue
+		}
+		delete(requests, legacyName)
+		if _, ok := requests[name]; !ok {
+			requests[name] = req
+		}
+	}
+
+	aliases, ok := resourceCommon.Aliases[string(name)]
+	if !ok {
+		aliases = []string{string(name)}
+	}
+	for _, alias := range aliases {
+		aliasName := corev1.ResourceName(alias)
+		req, ok := requests[aliasName]
+		if !ok {
+			continue
+		}
+		if !isStandardResource(aliasName) || req.Cmp(limit) > 0 {
+			requests[aliasName] = limit.DeepCopy()
+		}
+	}
+}
+
+// Standard resources can be requested below their limit, unlike extended resources
+func isStandardResource(name corev1.ResourceName) bool {
+	return name == corev1.ResourceCPU || name == corev1.ResourceMemory || name == corev1.ResourceEphemeralStorage
+}
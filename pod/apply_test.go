@@ -0,0 +1,260 @@
+package pod
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	resourceCommon "github.com/Netflix/titus-kube-common/resource"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ptr "k8s.io/utils/pointer"
+)
+
+func buildEmptyPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  "task-id-in-label",
+					Image: "my-registry.example.com/sample/helloworld:latest",
+				},
+			},
+		},
+	}
+}
+
+func buildFullConfig() *Config {
+	sgIDs := []string{"sg-1", "sg-2"}
+	subnetIDs := []string{"subnet-1", "subnet-2"}
+	return &Config{
+		AppArmorProfile:     ptr.StringPtr("localhost/docker_titus"),
+		AccountID:           ptr.StringPtr("123456"),
+		WorkloadDetail:      ptr.StringPtr("mydetail"),
+		WorkloadMetadata:    ptr.StringPtr("app-metadata"),
+		WorkloadMetadataSig: ptr.StringPtr("app-metadata-sig"),
+		WorkloadName:        ptr.StringPtr("myapp"),
+		WorkloadOwnerEmail:  ptr.StringPtr("test@example.com"),
+		WorkloadSequence:    ptr.StringPtr("v000"),
+		WorkloadStack:       ptr.StringPtr("mystack"),
+		AssignIPv6Address:   ptr.BoolPtr(true),
+		BytesEnabled:        ptr.BoolPtr(true),
+		CapabilitiesAdd:     []string{"SYS_ADMIN"},
+		CapabilitiesDrop:    []string{"NET_RAW"},
+		CapacityGroup:       ptr.StringPtr("DEFAULT"),
+		ContainerInfo:       ptr.StringPtr("cinfo"),
+		CPUBurstingEnabled:  ptr.BoolPtr(true),
+		EBSVolume: &EBSVolume{
+			VolumeID:  "vol-0123456789abcdef0",
+			MountPath: "/ebs",
+			MountPerm: EBSMountPermRW,
+			FSType:    "xfs",
+		},
+		EgressBandwidth:          stringToResourcePtr("10M"),
+		ElasticIPPool:            ptr.StringPtr("pool-1"),
+		ElasticIPs:               ptr.StringPtr("eip-1,eip-2"),
+		EntrypointShellSplitting: ptr.BoolPtr(false),
+		FuseEnabled:              ptr.BoolPtr(true),
+		HostnameStyle:            ptr.StringPtr("ec2"),
+		IAMRole:                  ptr.StringPtr("arn:aws:iam::0:role/DefaultContainerRole"),
+		IMDSRequireToken:         ptr.StringPtr("require-token"),
+		IngressBandwidth:         stringToResourcePtr("20M"),
+		JobAcceptedTimestampMs:   uint64Ptr(1602201163007),
+		JobDescriptor:            ptr.StringPtr("myjobdesc"),
+		JobID:                    ptr.StringPtr("myjobid"),
+		JobType:                  ptr.StringPtr("BATCH"),
+		JumboFramesEnabled:       ptr.BoolPtr(true),
+		KvmEnabled:               ptr.BoolPtr(false),
+		LogKeepLocalFile:         ptr.BoolPtr(true),
+		LogStdioCheckInterval:    durationPtr("2m"),
+		LogUploadCheckInterval:   durationPtr("1m30s"),
+		LogUploadThresholdTime:   durationPtr("3h"),
+		LogUploadRegExp:          regexp.MustCompile(".*.foo"),
+		LogS3BucketName:          ptr.StringPtr("bucket-name"),
+		LogS3PathPrefix:          ptr.StringPtr("s3-prefix"),
+		LogS3WriterIAMRole:       ptr.StringPtr("arn:aws:iam::0:role/LogWriterRole"),
+		NetworkMode:              ptr.StringPtr("example-network-mode"),
+		NetworkBurstingEnabled:   ptr.BoolPtr(true),
+		OomScoreAdj:              ptr.Int32Ptr(-800),
+		Opportunistic: &OpportunisticResources{
+			CPU:        stringToResourcePtr("4"),
+			ResourceID: ptr.StringPtr("op-res-id"),
+		},
+		PodSchemaVersion: uint32Ptr(2),
+		ResourceCPU:      stringToResourcePtr("1"),
+		ResourceDisk:     stringToResourcePtr("10Gi"),
+		ResourceMemory:   stringToResourcePtr("512Mi"),
+		ResourceNetwork:  stringToResourcePtr("128M"),
+		ResourceGPU:      stringToResourcePtr("0"),
+		RuntimePrediction: &RuntimePrediction{
+			Runtime:      durationPtr("44s"),
+			Confidence:   ptr.Float64Ptr(0.5),
+			ModelID:      ptr.StringPtr("model-id"),
+			ModelVersion: ptr.StringPtr("v2"),
+			ABTestCell:   ptr.StringPtr("cell1"),
+			Available: []PredictedRuntime{
+				{Confidence: 0.5, Runtime: 44 * time.Second},
+				{Confidence: 0.9, Runtime: time.Minute},
+			},
+			SelectorInfo: ptr.StringPtr("prediction"),
+		},
+		SchedPolicy:             ptr.StringPtr("idle"),
+		SeccompAgentNetEnabled:  ptr.BoolPtr(true),
+		SeccompAgentPerfEnabled: ptr.BoolPtr(true),
+		SeccompProfile: &corev1.SeccompProfile{
+			Type:             corev1.SeccompProfileTypeLocalhost,
+			LocalhostProfile: ptr.StringPtr("default.json"),
+		},
+		SecurityGroupIDs: &sgIDs,
+		Sidecars: []Sidecar{
+			{
+				Name:    "servicemesh",
+				Enabled: true,
+				Image:   "titusops/servicemesh:latest",
+				Params:  map[string]string{"port": "7001"},
+				Version: 2,
+			},
+		},
+		StaticIPAllocationUUID: ptr.StringPtr("static-ip-alloc-id"),
+		SubnetIDs:              &subnetIDs,
+		Sysctls:                map[string]string{"net.ipv4.conf.all.arp_ignore": "1"},
+		SystemEnvVarNames:      []string{"SYSTEM1", "SYSTEM2"},
+		TaskID:                 ptr.StringPtr("task-id-in-label"),
+		TTYEnabled:             ptr.BoolPtr(true),
+	}
+}
+
+func TestApplyConfigRoundTrip(t *testing.T) {
+	pod := buildEmptyPod()
+	expConf := buildFullConfig()
+	assert.NilError(t, ApplyConfig(pod, expConf))
+
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+
+	// You can't DeepEqual regexps, so test it separately
+	assert.Assert(t, conf.LogUploadRegExp != nil)
+	assert.Equal(t, conf.LogUploadRegExp.String(), expConf.LogUploadRegExp.String())
+	conf.LogUploadRegExp = nil
+	expConf.LogUploadRegExp = nil
+
+	// Containers are read from the pod spec, rather than written by ApplyConfig
+	assert.Equal(t, len(conf.Containers), 1)
+	assert.Equal(t, conf.Containers[0].Role, ContainerRoleWorkload)
+	conf.Containers = nil
+
+	assert.DeepEqual(t, *expConf, *conf)
+}
+
+func TestApplyConfigRoundTripFalseAndEmpty(t *testing.T) {
+	expConf := &Config{
+		AssignIPv6Address: ptr.BoolPtr(false),
+		BytesEnabled:      ptr.BoolPtr(false),
+		WorkloadName:      ptr.StringPtr(""),
+	}
+
+	pod := buildEmptyPod()
+	assert.NilError(t, ApplyConfig(pod, expConf))
+
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+	conf.Containers = nil
+	assert.DeepEqual(t, *expConf, *conf)
+
+	// Empty lists aren't written, so read back as unset
+	sgIDs := []string{}
+	pod = buildEmptyPod()
+	assert.NilError(t, ApplyConfig(pod, &Config{SecurityGroupIDs: &sgIDs, SystemEnvVarNames: []string{}}))
+	assert.DeepEqual(t, map[string]string{}, pod.Annotations)
+
+	conf, err = PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.Assert(t, conf.SecurityGroupIDs == nil)
+	assert.Assert(t, conf.SystemEnvVarNames == nil)
+
+	// The container's TTY field can't tell false apart from unset
+	pod = buildEmptyPod()
+	assert.NilError(t, ApplyConfig(pod, &Config{TTYEnabled: ptr.BoolPtr(false)}))
+	assert.Equal(t, pod.Spec.Containers[0].TTY, false)
+
+	conf, err = PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.Assert(t, conf.TTYEnabled == nil)
+}
+
+func TestApplyConfigEmpty(t *testing.T) {
+	pod := buildEmptyPod()
+	assert.NilError(t, ApplyConfig(pod, &Config{}))
+	assert.DeepEqual(t, map[string]string{}, pod.Annotations)
+	assert.DeepEqual(t, map[string]string{}, pod.Labels)
+
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+	conf.Containers = nil
+	assert.DeepEqual(t, Config{}, *conf)
+}
+
+func TestApplyConfigValues(t *testing.T) {
+	pod := buildEmptyPod()
+	conf := buildFullConfig()
+	assert.NilError(t, ApplyConfig(pod, conf))
+
+	assert.Equal(t, pod.Annotations[AnnotationKeyPrefixAppArmor+"/task-id-in-label"], "localhost/docker_titus")
+	assert.Equal(t, pod.Annotations[AnnotationKeyNetworkSecurityGroups], "sg-1,sg-2")
+	assert.Equal(t, pod.Annotations[AnnotationKeyLogUploadCheckInterval], "1m30s")
+	assert.Equal(t, pod.Annotations[AnnotationKeyPodOomScoreAdj], "-800")
+	assert.Equal(t, pod.Annotations[AnnotationKeyServicePrefix+"/servicemesh.v2.enabled"], "true")
+	assert.Equal(t, pod.Annotations[AnnotationKeyServicePrefix+"/servicemesh.v2.image"], "titusops/servicemesh:latest")
+	assert.Equal(t, pod.Labels[LabelKeyWorkloadName], "myapp")
+	assert.Equal(t, pod.Labels[LabelKeyByteUnitsEnabled], "true")
+
+	limits := pod.Spec.Containers[0].Resources.Limits
+	mem := limits[corev1.ResourceMemory]
+	assert.Equal(t, mem.String(), "512Mi")
+	network := limits[resourceCommon.ResourceNameNetwork]
+	assert.Equal(t, network.String(), "128M")
+	assert.Equal(t, pod.Spec.Containers[0].TTY, true)
+}
+
+func TestApplyConfigNoContainers(t *testing.T) {
+	pod := buildEmptyPod()
+	pod.Spec.Containers = nil
+	err := ApplyConfig(pod, buildFullConfig())
+	assert.ErrorContains(t, err, "no containers found in pod")
+}
+
+func TestApplyConfigRequests(t *testing.T) {
+	// buildPod sets requests equal to the limits
+	pod := buildPod(map[string]string{}, map[string]string{})
+	requests := pod.Spec.Containers[0].Resources.Requests
+	requests[resourceCommon.ResourceNameDiskLegacy] = resource.MustParse("20Gi")
+	delete(requests, corev1.ResourceEphemeralStorage)
+
+	assert.NilError(t, ApplyConfig(pod, &Config{
+		ResourceCPU:     stringToResourcePtr("500m"),
+		ResourceDisk:    stringToResourcePtr("5Gi"),
+		ResourceGPU:     stringToResourcePtr("1"),
+		ResourceMemory:  stringToResourcePtr("1Gi"),
+		ResourceNetwork: stringToResourcePtr("64M"),
+	}))
+
+	// Requests above the new limit are lowered, extended resource requests always match the
+	// limit, and legacy names are moved to the canonical one
+	reqStrs := map[corev1.ResourceName]string{}
+	for name, q := range pod.Spec.Containers[0].Resources.Requests {
+		reqStrs[name] = q.String()
+	}
+	assert.DeepEqual(t, map[corev1.ResourceName]string{
+		corev1.ResourceCPU:                 "500m",
+		corev1.ResourceEphemeralStorage:    "5Gi",
+		corev1.ResourceMemory:              "512Mi",
+		resourceCommon.ResourceNameGpu:     "1",
+		resourceCommon.ResourceNameNetwork: "64M",
+	}, reqStrs)
+}
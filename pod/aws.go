@@ -0,0 +1,127 @@
+package pod
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	multierror "github.com/hashicorp/go-multierror"
+)
+
+var (
+	accountIDRegexp       = regexp.MustCompile(`^[0-9]{12}$`)
+	iamRoleARNRegexp      = regexp.MustCompile(`^arn:aws(-[a-z]+)*:iam::([0-9]{12}):role/[\w+=,.@/-]+$`)
+	securityGroupIDRegexp = regexp.MustCompile(`^sg-([0-9a-f]{8}|[0-9a-f]{17})$`)
+	subnetIDRegexp        = regexp.MustCompile(`^subnet-([0-9a-f]{8}|[0-9a-f]{17})$`)
+)
+
+// ValidateAccountID checks that an AWS account ID is 12 digits
+func ValidateAccountID(accountID string) error {
+	if !accountIDRegexp.MatchString(accountID) {
+		return fmt.Errorf("account ID is not 12 digits: %q", accountID)
+	}
+	return nil
+}
+
+// ValidateIAMRoleARN checks that an IAM role ARN is well-formed, and returns the account ID in it
+func ValidateIAMRoleARN(arn string) (string, error) {
+	match := iamRoleARNRegexp.FindStringSubmatch(arn)
+	if match == nil {
+		return "", fmt.Errorf("IAM role is not a valid role ARN: %q", arn)
+	}
+	return match[2], nil
+}
+
+// ValidateSecurityGroupIDs checks that a list of security group IDs are all sg- IDs, without
+// empty or duplicate entries
+func ValidateSecurityGroupIDs(sgIDs []string) error {
+	return validateIDList(sgIDs, securityGroupIDRegexp, "security group ID")
+}
+
+// ValidateSubnetIDs checks that a list of subnet IDs are all subnet- IDs, without empty or
+// duplicate entries
+func ValidateSubnetIDs(subnetIDs []string) error {
+	return validateIDList(subnetIDs, subnetIDRegexp, "subnet ID")
+}
+
+func validateIDList(ids []string, re *regexp.Regexp, desc string) error {
+	var err *multierror.Error
+	seen := map[string]bool{}
+
+	for _, id := range ids {
+		switch {
+		case id == "":
+			err = multierror.Append(err, fmt.Errorf("list contains an empty %s", desc))
+		case !re.MatchString(id):
+			err = multierror.Append(err, fmt.Errorf("%s is not valid: %q", desc, id))
+		case seen[id]:
+			err = multierror.Append(err, fmt.Errorf("list contains a duplicate %s: %q", desc, id))
+		}
+		seen[id] = true
+	}
+
+	return err.ErrorOrNil()
+}
+
+// ValidateAWSIdentifiers checks the formats of the AWS identifiers in a config: the account ID,
+// IAM role, security groups and subnets, and that the IAM role is in the pod's account. Errors
+// are reported against the key each value came from, including legacy keys. PodToConfig doesn't
+// run these checks, so that existing pods with placeholder values still parse. They're opt-in:
+// the validating admission webhook runs them on new pods.
+func (c *Config) ValidateAWSIdentifiers() error {
+	var err *multierror.Error
+
+	keyFor := func(key, legacyKey string) string {
+		if containsString(c.LegacyKeysUsed, legacyKey) {
+			return legacyKey
+		}
+		return key
+	}
+
+	// Add a field error for each validation error, so list errors aren't nested
+	appendErrs := func(key, value string, expType KeyType, reason string, vErr error) {
+		vErrs := []error{vErr}
+		if mErr, ok := vErr.(*multierror.Error); ok {
+			vErrs = mErr.Errors
+		}
+		for _, e := range vErrs {
+			fErr := newFieldError(key, value, expType, reason)
+			fErr.Err = e
+			err = multierror.Append(err, fErr)
+		}
+	}
+
+	if c.AccountID != nil {
+		if vErr := ValidateAccountID(*c.AccountID); vErr != nil {
+			key := keyFor(AnnotationKeyNetworkAccountID, AnnotationKeyAccountIDLegacy)
+			appendErrs(key, *c.AccountID, KeyTypeString, "annotation is not a valid account ID", vErr)
+		}
+	}
+
+	if c.IAMRole != nil {
+		roleAccountID, vErr := ValidateIAMRoleARN(*c.IAMRole)
+		switch {
+		case vErr != nil:
+			appendErrs(AnnotationKeyIAMRole, *c.IAMRole, KeyTypeString, "annotation is not a valid IAM role", vErr)
+		case c.AccountID != nil && roleAccountID != *c.AccountID:
+			err = multierror.Append(err, newFieldError(AnnotationKeyIAMRole, *c.IAMRole, KeyTypeString,
+				"annotation does not match the account ID in "+keyFor(AnnotationKeyNetworkAccountID, AnnotationKeyAccountIDLegacy)))
+		}
+	}
+
+	if c.SecurityGroupIDs != nil {
+		if vErr := ValidateSecurityGroupIDs(*c.SecurityGroupIDs); vErr != nil {
+			key := keyFor(AnnotationKeyNetworkSecurityGroups, AnnotationKeySecurityGroupsLegacy)
+			appendErrs(key, strings.Join(*c.SecurityGroupIDs, ","), KeyTypeList, "annotation is not a valid list of security groups", vErr)
+		}
+	}
+
+	if c.SubnetIDs != nil {
+		if vErr := ValidateSubnetIDs(*c.SubnetIDs); vErr != nil {
+			key := keyFor(AnnotationKeyNetworkSubnetIDs, AnnotationKeySubnetsLegacy)
+			appendErrs(key, strings.Join(*c.SubnetIDs, ","), KeyTypeList, "annotation is not a valid list of subnets", vErr)
+		}
+	}
+
+	return err.ErrorOrNil()
+}
@@ -0,0 +1,74 @@
+package pod
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestValidateAccountID(t *testing.T) {
+	assert.NilError(t, ValidateAccountID("123456789012"))
+	assert.ErrorContains(t, ValidateAccountID("123456"), `account ID is not 12 digits: "123456"`)
+	assert.ErrorContains(t, ValidateAccountID("12345678901a"), "account ID is not 12 digits")
+}
+
+func TestValidateIAMRoleARN(t *testing.T) {
+	accountID, err := ValidateIAMRoleARN("arn:aws:iam::123456789012:role/path/My_Role-1")
+	assert.NilError(t, err)
+	assert.Equal(t, accountID, "123456789012")
+
+	accountID, err = ValidateIAMRoleARN("arn:aws-us-gov:iam::210987654321:role/MyRole")
+	assert.NilError(t, err)
+	assert.Equal(t, accountID, "210987654321")
+
+	for _, arn := range []string{
+		"MyRole",
+		"arn:aws:iam::0:role/MyRole",
+		"arn:aws:iam::123456789012:user/MyUser",
+		"arn:aws:iam::123456789012:role/",
+	} {
+		_, err = ValidateIAMRoleARN(arn)
+		assert.ErrorContains(t, err, "IAM role is not a valid role ARN", arn)
+	}
+}
+
+func TestValidateIDLists(t *testing.T) {
+	assert.NilError(t, ValidateSecurityGroupIDs([]string{"sg-11111111", "sg-0123456789abcdef0"}))
+	assert.NilError(t, ValidateSubnetIDs([]string{"subnet-11111111"}))
+
+	err := ValidateSecurityGroupIDs([]string{"sg-11111111", "", "sg-1", "subnet-22222222", "sg-11111111"})
+	assert.ErrorContains(t, err, "list contains an empty security group ID")
+	assert.ErrorContains(t, err, `security group ID is not valid: "sg-1"`)
+	assert.ErrorContains(t, err, `security group ID is not valid: "subnet-22222222"`)
+	assert.ErrorContains(t, err, `list contains a duplicate security group ID: "sg-11111111"`)
+
+	err = ValidateSubnetIDs([]string{"sg-11111111"})
+	assert.ErrorContains(t, err, `subnet ID is not valid: "sg-11111111"`)
+}
+
+func TestValidateAWSIdentifiers(t *testing.T) {
+	pod := buildPod(map[string]string{
+		AnnotationKeyIAMRole:               "arn:aws:iam::210987654321:role/MyRole",
+		AnnotationKeyNetworkAccountID:      "123456789012",
+		AnnotationKeyNetworkSecurityGroups: "sg-11111111,sg-1",
+		AnnotationKeySubnetsLegacy:         "subnet-11111111,subnet-11111111",
+	}, map[string]string{})
+
+	// Parsing doesn't check the formats, so existing pods still parse
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+	_, err = PodToConfigStrict(pod)
+	assert.NilError(t, err)
+
+	err = conf.ValidateAWSIdentifiers()
+	fErrs := FieldErrors(err)
+	assert.Equal(t, len(fErrs), 3)
+
+	assert.Equal(t, fErrs[0].Key, AnnotationKeyIAMRole)
+	assert.Equal(t, fErrs[0].Reason, "annotation does not match the account ID in "+AnnotationKeyNetworkAccountID)
+	assert.Equal(t, fErrs[1].Key, AnnotationKeyNetworkSecurityGroups)
+	assert.ErrorContains(t, fErrs[1].Err, `security group ID is not valid: "sg-1"`)
+	// Errors are reported against the key the value came from
+	assert.Equal(t, fErrs[2].Key, AnnotationKeySubnetsLegacy)
+	assert.ErrorContains(t, fErrs[2].Err, `list contains a duplicate subnet ID: "subnet-11111111"`)
+}
@@ -0,0 +1,69 @@
+package pod
+
+import (
+	"fmt"
+
+	resourceCommon "github.com/Netflix/titus-kube-common/resource"
+	multierror "github.com/hashicorp/go-multierror"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Bandwidth is a workload's network bandwidth limit in each direction, in bits/sec. A nil limit
+// means the direction isn't limited.
+type Bandwidth struct {
+	Egress  *resource.Quantity
+	Ingress *resource.Quantity
+}
+
+// EffectiveBandwidth returns the workload's bandwidth limits, in bits/sec. The titus/network
+// resource limit takes precedence, and applies in both directions. If it isn't set, the
+// kubernetes.io/egress-bandwidth and ingress-bandwidth annotations (which are always in bits/sec)
+// are used. It returns an error if an annotation is set to a different value than the resource
+// limit, along with the limits. PodToConfig doesn't check this, so pods with mismatched values
+// still parse.
+func (c *Config) EffectiveBandwidth() (*Bandwidth, error) {
+	network := c.ResourceNetworkBitsPerSecond()
+	if network == nil {
+		return &Bandwidth{
+			Egress:  copyQuantity(c.EgressBandwidth),
+			Ingress: copyQuantity(c.IngressBandwidth),
+		}, nil
+	}
+
+	var err *multierror.Error
+	annotations := []struct {
+		key string
+		val *resource.Quantity
+	}{
+		{
+			key: AnnotationKeyEgressBandwidth,
+			val: c.EgressBandwidth,
+		},
+		{
+			key: AnnotationKeyIngressBandwidth,
+			val: c.IngressBandwidth,
+		},
+	}
+	for _, a := range annotations {
+		if a.val != nil && a.val.Cmp(*network) != 0 {
+			fErr := newFieldError(a.key, a.val.String(), KeyTypeQuantity,
+				"annotation does not match the "+resourceCommon.ResourceNameNetwork+" resource limit")
+			fErr.Err = fmt.Errorf("%s != %s bits/sec", a.val.String(), network.String())
+			err = multierror.Append(err, fErr)
+		}
+	}
+
+	egress := network.DeepCopy()
+	return &Bandwidth{
+		Egress:  &egress,
+		Ingress: network,
+	}, err.ErrorOrNil()
+}
+
+func copyQuantity(q *resource.Quantity) *resource.Quantity {
+	if q == nil {
+		return nil
+	}
+	c := q.DeepCopy()
+	return &c
+}
@@ -0,0 +1,84 @@
+package pod
+
+import (
+	"testing"
+
+	resourceCommon "github.com/Netflix/titus-kube-common/resource"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	ptr "k8s.io/utils/pointer"
+)
+
+func TestEffectiveBandwidthAnnotations(t *testing.T) {
+	conf := &Config{
+		EgressBandwidth: stringToResourcePtr("10M"),
+	}
+
+	bw, err := conf.EffectiveBandwidth()
+	assert.NilError(t, err)
+	assert.Equal(t, bw.Egress.String(), "10M")
+	assert.Assert(t, bw.Ingress == nil)
+
+	bw, err = (&Config{}).EffectiveBandwidth()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, bw, &Bandwidth{})
+}
+
+func TestEffectiveBandwidthResourceLimit(t *testing.T) {
+	// Without byte units, the resource limit is in Mbps
+	conf := &Config{
+		IngressBandwidth: stringToResourcePtr("128M"),
+		ResourceNetwork:  stringToResourcePtr("128"),
+	}
+
+	bw, err := conf.EffectiveBandwidth()
+	assert.NilError(t, err)
+	assert.Equal(t, bw.Egress.String(), "128M")
+	assert.Equal(t, bw.Ingress.String(), "128M")
+
+	// A legacy limit that's already in bits/sec isn't scaled again
+	pod := buildPod(map[string]string{
+		AnnotationKeyEgressBandwidth: "128M",
+	}, map[string]string{})
+	network := pod.Spec.Containers[0].Resources.Limits[resourceCommon.ResourceNameNetwork]
+	assert.Equal(t, network.String(), "128M")
+	legacyConf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.Assert(t, !legacyConf.byteUnits())
+
+	bw, err = legacyConf.EffectiveBandwidth()
+	assert.NilError(t, err)
+	assert.Equal(t, bw.Egress.String(), "128M")
+	assert.Equal(t, bw.Ingress.String(), "128M")
+
+	conf.BytesEnabled = ptr.BoolPtr(true)
+	conf.ResourceNetwork = stringToResourcePtr("128M")
+	conf.EgressBandwidth = stringToResourcePtr("128000k")
+
+	bw, err = conf.EffectiveBandwidth()
+	assert.NilError(t, err)
+	assert.Equal(t, bw.Egress.String(), "128M")
+}
+
+func TestEffectiveBandwidthMismatch(t *testing.T) {
+	pod := buildPod(map[string]string{
+		AnnotationKeyIngressBandwidth: "1G",
+	}, map[string]string{})
+	pod.Spec.Containers[0].Resources.Limits = corev1.ResourceList{
+		resourceCommon.ResourceNameNetwork: resource.MustParse("128"),
+	}
+
+	// The pod still parses, but EffectiveBandwidth reports the mismatch
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+
+	bw, err := conf.EffectiveBandwidth()
+	fErrs := FieldErrors(err)
+	assert.Equal(t, len(fErrs), 1)
+	assert.Equal(t, fErrs[0].Key, AnnotationKeyIngressBandwidth)
+	assert.Error(t, fErrs[0].Err, "1G != 128M bits/sec")
+
+	// The resource limit still takes precedence
+	assert.Equal(t, bw.Ingress.String(), "128M")
+}
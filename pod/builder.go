@@ -0,0 +1,151 @@
+package pod
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Builder builds Titus pods. Values are set on a Config, which is written onto the pod with
+// ApplyConfig when Build is called.
+type Builder struct {
+	pod  *corev1.Pod
+	conf *Config
+}
+
+// NewBuilder returns a builder for a pod with a single workload container, both named after
+// the task ID
+func NewBuilder(taskID string) *Builder {
+	schemaVersion := uint32(NormalizedPodSchemaVersion)
+	return &Builder{
+		pod: &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      taskID,
+				Namespace: "default",
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: taskID,
+					},
+				},
+			},
+		},
+		conf: &Config{
+			PodSchemaVersion: &schemaVersion,
+			TaskID:           &taskID,
+		},
+	}
+}
+
+// WithConfig lets the caller set any Config values that don't have a dedicated method
+func (b *Builder) WithConfig(f func(conf *Config)) *Builder {
+	f(b.conf)
+	return b
+}
+
+func (b *Builder) WithImage(image string) *Builder {
+	b.pod.Spec.Containers[0].Image = image
+	return b
+}
+
+func (b *Builder) WithJob(jobID, jobType string) *Builder {
+	b.conf.JobID = &jobID
+	b.conf.JobType = &jobType
+	return b
+}
+
+func (b *Builder) WithWorkload(name, stack, detail, sequence string) *Builder {
+	b.conf.WorkloadName = &name
+	b.conf.WorkloadStack = &stack
+	b.conf.WorkloadDetail = &detail
+	b.conf.WorkloadSequence = &sequence
+	return b
+}
+
+func (b *Builder) WithCapacityGroup(capacityGroup string) *Builder {
+	b.conf.CapacityGroup = &capacityGroup
+	return b
+}
+
+func (b *Builder) WithSecurityGroups(sgIDs ...string) *Builder {
+	b.conf.SecurityGroupIDs = &sgIDs
+	return b
+}
+
+func (b *Builder) WithSubnets(subnetIDs ...string) *Builder {
+	b.conf.SubnetIDs = &subnetIDs
+	return b
+}
+
+func (b *Builder) WithIAMRole(role string) *Builder {
+	b.conf.IAMRole = &role
+	return b
+}
+
+// WithSidecar adds an enabled sidecar
+func (b *Builder) WithSidecar(name string, version int, image string) *Builder {
+	b.conf.Sidecars = append(b.conf.Sidecars, Sidecar{
+		Name:    name,
+		Version: version,
+		Image:   image,
+		Enabled: true,
+	})
+	return b
+}
+
+// WithResources sets the workload container's resource limits. Memory and disk are in bytes,
+// and network in bits/sec, so this also marks the pod as using byte units.
+func (b *Builder) WithResources(cpu, memory, disk, network resource.Quantity) *Builder {
+	bytesEnabled := true
+	b.conf.BytesEnabled = &bytesEnabled
+	b.conf.ResourceCPU = &cpu
+	b.conf.ResourceMemory = &memory
+	b.conf.ResourceDisk = &disk
+	b.conf.ResourceNetwork = &network
+	return b
+}
+
+func (b *Builder) WithGPU(gpu resource.Quantity) *Builder {
+	b.conf.ResourceGPU = &gpu
+	return b
+}
+
+// WithSchedPolicy sets the scheduler policy: either "batch" or "idle"
+func (b *Builder) WithSchedPolicy(policy string) *Builder {
+	b.conf.SchedPolicy = &policy
+	return b
+}
+
+func (b *Builder) WithAnnotation(key, value string) *Builder {
+	if b.pod.Annotations == nil {
+		b.pod.Annotations = map[string]string{}
+	}
+	b.pod.Annotations[key] = value
+	return b
+}
+
+func (b *Builder) WithLabel(key, value string) *Builder {
+	if b.pod.Labels == nil {
+		b.pod.Labels = map[string]string{}
+	}
+	b.pod.Labels[key] = value
+	return b
+}
+
+// Build returns the pod, checking that it can be parsed with PodToConfig. The builder can be
+// used again afterwards: each call returns a new pod.
+func (b *Builder) Build() (*corev1.Pod, error) {
+	pod := b.pod.DeepCopy()
+	if err := ApplyConfig(pod, b.conf); err != nil {
+		return nil, err
+	}
+
+	if _, err := PodToConfig(pod); err != nil {
+		return nil, fmt.Errorf("built pod is not valid: %w", err)
+	}
+
+	return pod, nil
+}
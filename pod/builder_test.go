@@ -0,0 +1,78 @@
+package pod
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	ptr "k8s.io/utils/pointer"
+)
+
+func TestBuilder(t *testing.T) {
+	pod, err := NewBuilder("task-id").
+		WithImage("titusops/alpine:latest").
+		WithJob("job-id", "BATCH").
+		WithWorkload("myapp", "mystack", "mydetail", "v001").
+		WithCapacityGroup("DEFAULT").
+		WithSecurityGroups("sg-1", "sg-2").
+		WithSubnets("subnet-1").
+		WithSidecar("servicemesh", 2, "titusops/servicemesh:latest").
+		WithResources(resource.MustParse("2"), resource.MustParse("512Mi"), resource.MustParse("10Gi"), resource.MustParse("128M")).
+		WithGPU(resource.MustParse("1")).
+		WithSchedPolicy("batch").
+		WithConfig(func(conf *Config) {
+			conf.KvmEnabled = ptr.BoolPtr(true)
+		}).
+		WithAnnotation("example.com/extra", "value").
+		WithLabel("example.com/extra", "value").
+		Build()
+	assert.NilError(t, err)
+
+	assert.Equal(t, pod.Name, "task-id")
+	assert.Equal(t, pod.Spec.Containers[0].Name, "task-id")
+	assert.Equal(t, pod.Spec.Containers[0].Image, "titusops/alpine:latest")
+	assert.Equal(t, pod.Annotations["example.com/extra"], "value")
+	assert.Equal(t, pod.Labels["example.com/extra"], "value")
+	assert.Equal(t, pod.Labels[LabelKeyWorkloadName], "myapp")
+	cpu := pod.Spec.Containers[0].Resources.Limits[corev1.ResourceCPU]
+	assert.Equal(t, cpu.String(), "2")
+
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ptr.StringPtr("task-id"), conf.TaskID)
+	assert.DeepEqual(t, uint32Ptr(1), conf.PodSchemaVersion)
+	assert.DeepEqual(t, ptr.StringPtr("job-id"), conf.JobID)
+	assert.DeepEqual(t, ptr.StringPtr("mystack"), conf.WorkloadStack)
+	assert.DeepEqual(t, ptr.StringPtr("DEFAULT"), conf.CapacityGroup)
+	assert.DeepEqual(t, &[]string{"sg-1", "sg-2"}, conf.SecurityGroupIDs)
+	assert.DeepEqual(t, &[]string{"subnet-1"}, conf.SubnetIDs)
+	assert.DeepEqual(t, []Sidecar{
+		{Name: "servicemesh", Version: 2, Image: "titusops/servicemesh:latest", Enabled: true},
+	}, conf.Sidecars)
+	assert.DeepEqual(t, ptr.BoolPtr(true), conf.BytesEnabled)
+	assert.DeepEqual(t, stringToResourcePtr("512Mi"), conf.ResourceMemory)
+	assert.DeepEqual(t, stringToResourcePtr("1"), conf.ResourceGPU)
+	assert.DeepEqual(t, ptr.StringPtr("batch"), conf.SchedPolicy)
+	assert.DeepEqual(t, ptr.BoolPtr(true), conf.KvmEnabled)
+}
+
+func TestBuilderInvalid(t *testing.T) {
+	_, err := NewBuilder("task-id").WithSchedPolicy("fifo").Build()
+	assert.ErrorContains(t, err, "built pod is not valid: ")
+	assert.ErrorContains(t, err, "annotation is not a valid scheduler policy: "+AnnotationKeyPodSchedPolicy)
+
+	_, err = NewBuilder("task-id").WithSidecar("servicemesh", 2, "no-tag").Build()
+	assert.ErrorContains(t, err, "error parsing service image annotation")
+}
+
+func TestBuilderReuse(t *testing.T) {
+	builder := NewBuilder("task-id")
+	pod1, err := builder.WithCapacityGroup("first").Build()
+	assert.NilError(t, err)
+	pod2, err := builder.WithCapacityGroup("second").Build()
+	assert.NilError(t, err)
+
+	assert.Equal(t, pod1.Labels[LabelKeyCapacityGroup], "first")
+	assert.Equal(t, pod2.Labels[LabelKeyCapacityGroup], "second")
+}
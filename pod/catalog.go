@@ -0,0 +1,554 @@
+package pod
+
+import (
+	"sort"
+	"strings"
+)
+
+// KeyType describes the format of a label or annotation value
+type KeyType string
+
+const (
+	KeyTypeString     KeyType = "string"
+	KeyTypeBool       KeyType = "bool"
+	KeyTypeInteger    KeyType = "integer"
+	KeyTypeFloat      KeyType = "float"
+	KeyTypeDuration   KeyType = "duration"
+	KeyTypeQuantity   KeyType = "quantity"
+	KeyTypeRegexp     KeyType = "regexp"
+	KeyTypeList       KeyType = "list"
+	KeyTypeIPAddress  KeyType = "ip-address"
+	KeyTypeMACAddress KeyType = "mac-address"
+	// KeyTypeStructured values have a custom format, described in the key's description
+	KeyTypeStructured KeyType = "structured"
+)
+
+// Components that own (set, or define the meaning of) Titus labels and annotations
+const (
+	ComponentControlPlane = "control-plane"
+	ComponentScheduler    = "scheduler"
+	ComponentCNI          = "cni"
+	ComponentNode         = "node"
+	ComponentKubernetes   = "kubernetes"
+)
+
+// KeyInfo describes a label or annotation key used on Titus pods
+type KeyInfo struct {
+	Key string
+	// Prefix is true if Key is a prefix, followed by a container or service name and parameters
+	Prefix      bool
+	Type        KeyType
+	Component   string
+	Description string
+	// AllowedValues, if set, lists the only values the key may have
+	AllowedValues []string
+	Deprecated    bool
+	// Replacement is the key to use instead of a deprecated key, if there is one
+	Replacement string
+}
+
+// keySpec is a catalog entry, along with how to parse it into a Config
+type keySpec struct {
+	KeyInfo
+	// field returns a pointer to the Config field that the annotation is parsed into. It's
+	// nil for keys that are parsed by dedicated code, or aren't parsed into a Config at all.
+	field func(c *Config) interface{}
+	// allowedDesc describes the allowed values in errors, eg: "scheduler policy"
+	allowedDesc string
+}
+
+// copyKeyInfo returns the spec's KeyInfo, with its own copy of AllowedValues. Some specs share
+// their allowed values with the parser, so callers mustn't be able to modify them.
+func (spec *keySpec) copyKeyInfo() KeyInfo {
+	info := spec.KeyInfo
+	if info.AllowedValues != nil {
+		info.AllowedValues = append([]string(nil), info.AllowedValues...)
+	}
+	return info
+}
+
+var annotationCatalog = []keySpec{
+	// node
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyInstanceType, Type: KeyTypeString, Component: ComponentNode,
+			Description: "EC2 instance type of the node the pod is running on"},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyRegion, Type: KeyTypeString, Component: ComponentNode,
+			Description: "AWS region of the node the pod is running on"},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyStack, Type: KeyTypeString, Component: ComponentNode,
+			Description: "Titus stack of the node the pod is running on"},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyAZ, Type: KeyTypeString, Component: ComponentKubernetes,
+			Description: "availability zone of the node the pod is running on",
+			Deprecated:  true, Replacement: "topology.kubernetes.io/zone"},
+	},
+
+	// pod networking
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyEgressBandwidth, Type: KeyTypeQuantity, Component: ComponentKubernetes,
+			Description: "egress bandwidth limit, in bits/sec"},
+		field: func(c *Config) interface{} { return &c.EgressBandwidth },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyIngressBandwidth, Type: KeyTypeQuantity, Component: ComponentKubernetes,
+			Description: "ingress bandwidth limit, in bits/sec"},
+		field: func(c *Config) interface{} { return &c.IngressBandwidth },
+	},
+
+	// pod ENI
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyIPv4Address, Type: KeyTypeIPAddress, Component: ComponentCNI,
+			Description: "IPv4 address assigned to the pod"},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyIPv4PrefixLength, Type: KeyTypeInteger, Component: ComponentCNI,
+			Description: "prefix length of the IPv4 address assigned to the pod"},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyIPv6Address, Type: KeyTypeIPAddress, Component: ComponentCNI,
+			Description: "IPv6 address assigned to the pod"},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyIPv6PrefixLength, Type: KeyTypeInteger, Component: ComponentCNI,
+			Description: "prefix length of the IPv6 address assigned to the pod"},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyBranchEniID, Type: KeyTypeString, Component: ComponentCNI,
+			Description: "ID of the branch ENI the pod's traffic is sent over"},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyBranchEniMac, Type: KeyTypeMACAddress, Component: ComponentCNI,
+			Description: "MAC address of the branch ENI"},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyBranchEniVpcID, Type: KeyTypeString, Component: ComponentCNI,
+			Description: "VPC ID of the branch ENI"},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyBranchEniSubnet, Type: KeyTypeString, Component: ComponentCNI,
+			Description: "subnet ID of the branch ENI"},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyTrunkEniID, Type: KeyTypeString, Component: ComponentCNI,
+			Description: "ID of the trunk ENI the branch ENI is attached to"},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyTrunkEniMac, Type: KeyTypeMACAddress, Component: ComponentCNI,
+			Description: "MAC address of the trunk ENI"},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyTrunkEniVpcID, Type: KeyTypeString, Component: ComponentCNI,
+			Description: "VPC ID of the trunk ENI"},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyVlanID, Type: KeyTypeInteger, Component: ComponentCNI,
+			Description: "VLAN ID of the branch ENI on the trunk ENI"},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyAllocationIdx, Type: KeyTypeInteger, Component: ComponentCNI,
+			Description: "index of the pod's network allocation on the node"},
+	},
+
+	// security
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyIAMRole, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description: "ARN of the IAM role the workload runs as (matches kube2iam)"},
+		field: func(c *Config) interface{} { return &c.IAMRole },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeySecurityGroupsLegacy, Type: KeyTypeList, Component: ComponentControlPlane,
+			Description: "comma-separated security group IDs",
+			Deprecated:  true, Replacement: AnnotationKeyNetworkSecurityGroups},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyPrefixAppArmor, Prefix: true, Type: KeyTypeString, Component: ComponentKubernetes,
+			Description: "AppArmor profile of a container, suffixed with /<container name>"},
+	},
+
+	// v1 pod spec
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyPodSchemaVersion, Type: KeyTypeInteger, Component: ComponentControlPlane,
+			Description: "schema version the pod was created with"},
+		field: func(c *Config) interface{} { return &c.PodSchemaVersion },
+	},
+
+	// workload
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyWorkloadDetail, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description: "workload detail"},
+		field: func(c *Config) interface{} { return &c.WorkloadDetail },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyWorkloadName, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description: "workload (application) name"},
+		field: func(c *Config) interface{} { return &c.WorkloadName },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyWorkloadOwnerEmail, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description: "email address of the workload owner"},
+		field: func(c *Config) interface{} { return &c.WorkloadOwnerEmail },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyWorkloadSequence, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description: "workload sequence, eg: v001"},
+		field: func(c *Config) interface{} { return &c.WorkloadSequence },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyWorkloadStack, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description: "workload stack"},
+		field: func(c *Config) interface{} { return &c.WorkloadStack },
+	},
+
+	// Titus job
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyJobAcceptedTimestampMs, Type: KeyTypeInteger, Component: ComponentControlPlane,
+			Description: "time the job was accepted, in milliseconds since the epoch"},
+		field: func(c *Config) interface{} { return &c.JobAcceptedTimestampMs },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyJobID, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description: "Titus job ID"},
+		field: func(c *Config) interface{} { return &c.JobID },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyJobType, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description: "Titus job type, eg: BATCH or SERVICE"},
+		field: func(c *Config) interface{} { return &c.JobType },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyJobDescriptor, Type: KeyTypeStructured, Component: ComponentControlPlane,
+			Description: "base64 encoded, gzipped JSON job descriptor"},
+		field: func(c *Config) interface{} { return &c.JobDescriptor },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyPodTitusContainerInfo, Type: KeyTypeStructured, Component: ComponentControlPlane,
+			Description: "base64 encoded containerInfo, to be removed once VK supports the full pod spec",
+			Deprecated:  true},
+		field: func(c *Config) interface{} { return &c.ContainerInfo },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyPodTitusEntrypointShellSplitting, Type: KeyTypeBool, Component: ComponentControlPlane,
+			Description: "preserve the legacy entrypoint shell splitting behaviour"},
+		field: func(c *Config) interface{} { return &c.EntrypointShellSplitting },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyPodTitusSystemEnvVarNames, Type: KeyTypeList, Component: ComponentControlPlane,
+			Description: "comma-separated names of the system-specified environment variables"},
+		field: func(c *Config) interface{} { return &c.SystemEnvVarNames },
+	},
+
+	// networking
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeySubnetsLegacy, Type: KeyTypeList, Component: ComponentControlPlane,
+			Description: "comma-separated subnet IDs",
+			Deprecated:  true, Replacement: AnnotationKeyNetworkSubnetIDs},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyAccountIDLegacy, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description: "AWS account ID the pod's network is in",
+			Deprecated:  true, Replacement: AnnotationKeyNetworkAccountID},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyNetworkAccountID, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description: "AWS account ID the pod's network is in"},
+		field: func(c *Config) interface{} { return &c.AccountID },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyNetworkBurstingEnabled, Type: KeyTypeBool, Component: ComponentControlPlane,
+			Description: "allow the pod to burst above its network bandwidth"},
+		field: func(c *Config) interface{} { return &c.NetworkBurstingEnabled },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyNetworkAssignIPv6Address, Type: KeyTypeBool, Component: ComponentControlPlane,
+			Description: "assign an IPv6 address to the pod"},
+		field: func(c *Config) interface{} { return &c.AssignIPv6Address },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyNetworkElasticIPPool, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description: "name of the elastic IP pool to assign an address from"},
+		field: func(c *Config) interface{} { return &c.ElasticIPPool },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyNetworkElasticIPs, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description: "comma-separated elastic IP allocation IDs to assign an address from"},
+		field: func(c *Config) interface{} { return &c.ElasticIPs },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyNetworkIMDSRequireToken, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description: "require a token for instance metadata service requests"},
+		field: func(c *Config) interface{} { return &c.IMDSRequireToken },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyNetworkJumboFramesEnabled, Type: KeyTypeBool, Component: ComponentControlPlane,
+			Description: "enable jumbo frames on the pod's network interface"},
+		field: func(c *Config) interface{} { return &c.JumboFramesEnabled },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyNetworkMode, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description: "network mode of the pod"},
+		field: func(c *Config) interface{} { return &c.NetworkMode },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyNetworkSecurityGroups, Type: KeyTypeList, Component: ComponentControlPlane,
+			Description: "comma-separated security group IDs"},
+		field: func(c *Config) interface{} { return &c.SecurityGroupIDs },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyNetworkSubnetIDs, Type: KeyTypeList, Component: ComponentControlPlane,
+			Description: "comma-separated subnet IDs"},
+		field: func(c *Config) interface{} { return &c.SubnetIDs },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyNetworkStaticIPAllocationUUID, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description: "UUID of the static IP allocation to assign an address from"},
+		field: func(c *Config) interface{} { return &c.StaticIPAllocationUUID },
+	},
+
+	// storage
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyStorageEBSVolumeID, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description: "ID of the EBS volume to attach"},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyStorageEBSMountPath, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description: "absolute path to mount the EBS volume at"},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyStorageEBSMountPerm, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description: "permissions to mount the EBS volume with", AllowedValues: ebsMountPerms},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyStorageEBSFSType, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description: "filesystem type of the EBS volume", AllowedValues: ebsFSTypes},
+	},
+
+	// security
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeySecurityWorkloadMetadata, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description: "Metatron workload metadata"},
+		field: func(c *Config) interface{} { return &c.WorkloadMetadata },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeySecurityWorkloadMetadataSig, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description: "Metatron workload metadata signature"},
+		field: func(c *Config) interface{} { return &c.WorkloadMetadataSig },
+	},
+
+	// opportunistic resources
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyOpportunisticCPU, Type: KeyTypeQuantity, Component: ComponentScheduler,
+			Description: "number of opportunistic CPUs assigned to the pod"},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyOpportunisticResourceID, Type: KeyTypeString, Component: ComponentScheduler,
+			Description: "name of the opportunistic resource CRD used during scheduling"},
+	},
+
+	// predictions
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyPredictionRuntime, Type: KeyTypeDuration, Component: ComponentScheduler,
+			Description: "predicted runtime"},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyPredictionConfidence, Type: KeyTypeFloat, Component: ComponentScheduler,
+			Description: "confidence (percentile) of the predicted runtime, between 0 and 1"},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyPredictionModelID, Type: KeyTypeString, Component: ComponentScheduler,
+			Description: "UUID of the model used for the runtime prediction"},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyPredictionModelVersion, Type: KeyTypeString, Component: ComponentScheduler,
+			Description: "version of the model used for the runtime prediction"},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyPredictionABTestCell, Type: KeyTypeString, Component: ComponentScheduler,
+			Description: "cell allocation for prediction AB tests"},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyPredictionPredictionAvailable, Type: KeyTypeStructured, Component: ComponentScheduler,
+			Description: "predictions available during job admission, as semicolon-separated confidence=runtime pairs"},
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyPredictionSelectorInfo, Type: KeyTypeString, Component: ComponentScheduler,
+			Description: "opaque metadata from the prediction selection algorithm"},
+	},
+
+	// pod features
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyPodCPUBurstingEnabled, Type: KeyTypeBool, Component: ComponentControlPlane,
+			Description: "allow the pod to burst above its CPU limit"},
+		field: func(c *Config) interface{} { return &c.CPUBurstingEnabled },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyPodKvmEnabled, Type: KeyTypeBool, Component: ComponentControlPlane,
+			Description: "give the pod access to KVM"},
+		field: func(c *Config) interface{} { return &c.KvmEnabled },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyPodFuseEnabled, Type: KeyTypeBool, Component: ComponentControlPlane,
+			Description: "give the pod access to FUSE"},
+		field: func(c *Config) interface{} { return &c.FuseEnabled },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyPodHostnameStyle, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description: "style of the pod's hostname", AllowedValues: []string{"", HostnameStyleEC2}},
+		field:       func(c *Config) interface{} { return &c.HostnameStyle },
+		allowedDesc: "hostname style",
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyPodOomScoreAdj, Type: KeyTypeInteger, Component: ComponentControlPlane,
+			Description: "OOM score adjustment for the workload's processes"},
+		field: func(c *Config) interface{} { return &c.OomScoreAdj },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyPodSchedPolicy, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description: "Linux scheduler policy for the workload's processes", AllowedValues: []string{"batch", "idle"}},
+		field:       func(c *Config) interface{} { return &c.SchedPolicy },
+		allowedDesc: "scheduler policy",
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyPodSeccompAgentNetEnabled, Type: KeyTypeBool, Component: ComponentControlPlane,
+			Description: "enable the seccomp agent for networking syscalls"},
+		field: func(c *Config) interface{} { return &c.SeccompAgentNetEnabled },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyPodSeccompAgentPerfEnabled, Type: KeyTypeBool, Component: ComponentControlPlane,
+			Description: "enable the seccomp agent for perf syscalls"},
+		field: func(c *Config) interface{} { return &c.SeccompAgentPerfEnabled },
+	},
+
+	// container annotations
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyPrefixContainerType, Prefix: true, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description:   "type of a container, suffixed with the container name",
+			AllowedValues: []string{AnnotationValueContainerTypePlatformSidecar}},
+	},
+
+	// logging
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyLogKeepLocalFile, Type: KeyTypeBool, Component: ComponentControlPlane,
+			Description: "keep log files on local disk after they're uploaded"},
+		field: func(c *Config) interface{} { return &c.LogKeepLocalFile },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyLogS3BucketName, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description: "S3 bucket to upload logs to"},
+		field: func(c *Config) interface{} { return &c.LogS3BucketName },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyLogS3PathPrefix, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description: "S3 key prefix to upload logs under"},
+		field: func(c *Config) interface{} { return &c.LogS3PathPrefix },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyLogS3WriterIAMRole, Type: KeyTypeString, Component: ComponentControlPlane,
+			Description: "ARN of the IAM role to upload logs as"},
+		field: func(c *Config) interface{} { return &c.LogS3WriterIAMRole },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyLogStdioCheckInterval, Type: KeyTypeDuration, Component: ComponentControlPlane,
+			Description: "how often to check stdout and stderr for rotation"},
+		field: func(c *Config) interface{} { return &c.LogStdioCheckInterval },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyLogUploadThresholdTime, Type: KeyTypeDuration, Component: ComponentControlPlane,
+			Description: "how long after a log file was last modified to upload it"},
+		field: func(c *Config) interface{} { return &c.LogUploadThresholdTime },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyLogUploadCheckInterval, Type: KeyTypeDuration, Component: ComponentControlPlane,
+			Description: "how often to check for log files to upload"},
+		field: func(c *Config) interface{} { return &c.LogUploadCheckInterval },
+	},
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyLogUploadRegexp, Type: KeyTypeRegexp, Component: ComponentControlPlane,
+			Description: "regexp matching the log files to upload"},
+		field: func(c *Config) interface{} { return &c.LogUploadRegExp },
+	},
+
+	// service configuration
+	{
+		KeyInfo: KeyInfo{Key: AnnotationKeyServicePrefix, Prefix: true, Type: KeyTypeStructured, Component: ComponentControlPlane,
+			Description: "sidecar service configuration, as <prefix>/<name>.v<version>.<parameter>"},
+	},
+}
+
+var labelCatalog = []KeyInfo{
+	// legacy job details
+	{Key: LabelKeyAppLegacy, Type: KeyTypeString, Component: ComponentControlPlane,
+		Description: "workload (application) name", Deprecated: true, Replacement: LabelKeyWorkloadName},
+	{Key: LabelKeyDetailLegacy, Type: KeyTypeString, Component: ComponentControlPlane,
+		Description: "workload detail", Deprecated: true, Replacement: LabelKeyWorkloadDetail},
+	{Key: LabelKeySequenceLegacy, Type: KeyTypeString, Component: ComponentControlPlane,
+		Description: "workload sequence", Deprecated: true, Replacement: LabelKeyWorkloadSequence},
+	{Key: LabelKeyStackLegacy, Type: KeyTypeString, Component: ComponentControlPlane,
+		Description: "workload stack", Deprecated: true, Replacement: LabelKeyWorkloadStack},
+	{Key: LabelKeyCapacityGroupLegacy, Type: KeyTypeString, Component: ComponentControlPlane,
+		Description: "capacity group the job runs in", Deprecated: true, Replacement: LabelKeyCapacityGroup},
+
+	{Key: LabelKeyByteUnitsEnabled, Type: KeyTypeBool, Component: ComponentControlPlane,
+		Description: "resources are specified in bytes, rather than MiB and Mbps"},
+
+	// v1 pod labels
+	{Key: LabelKeyJobId, Type: KeyTypeString, Component: ComponentControlPlane,
+		Description: "Titus job ID"},
+	{Key: LabelKeyTaskId, Type: KeyTypeString, Component: ComponentControlPlane,
+		Description: "Titus task ID"},
+	{Key: LabelKeyCapacityGroup, Type: KeyTypeString, Component: ComponentControlPlane,
+		Description: "capacity group the job runs in"},
+	{Key: LabelKeyWorkloadName, Type: KeyTypeString, Component: ComponentControlPlane,
+		Description: "workload (application) name"},
+	{Key: LabelKeyWorkloadStack, Type: KeyTypeString, Component: ComponentControlPlane,
+		Description: "workload stack"},
+	{Key: LabelKeyWorkloadDetail, Type: KeyTypeString, Component: ComponentControlPlane,
+		Description: "workload detail"},
+	{Key: LabelKeyWorkloadSequence, Type: KeyTypeString, Component: ComponentControlPlane,
+		Description: "workload sequence, eg: v001"},
+}
+
+// KnownAnnotations returns a description of every annotation used on Titus pods, sorted by key
+func KnownAnnotations() []KeyInfo {
+	known := []KeyInfo{}
+	for _, spec := range annotationCatalog {
+		known = append(known, spec.copyKeyInfo())
+	}
+	sort.Slice(known, func(i, j int) bool {
+		return known[i].Key < known[j].Key
+	})
+	return known
+}
+
+// LookupAnnotation returns the description of an annotation key. Keys under a known prefix (such
+// as a sidecar service's configuration) return the description of the prefix.
+func LookupAnnotation(key string) (KeyInfo, bool) {
+	for _, spec := range annotationCatalog {
+		if spec.Key == key || (spec.Prefix && strings.HasPrefix(key, spec.Key)) {
+			return spec.copyKeyInfo(), true
+		}
+	}
+	return KeyInfo{}, false
+}
+
+// KnownLabels returns a description of every label used on Titus pods, sorted by key
+func KnownLabels() []KeyInfo {
+	known := append([]KeyInfo{}, labelCatalog...)
+	sort.Slice(known, func(i, j int) bool {
+		return known[i].Key < known[j].Key
+	})
+	return known
+}
+
+// LookupLabel returns the description of a label key
+func LookupLabel(key string) (KeyInfo, bool) {
+	for _, info := range labelCatalog {
+		if info.Key == key {
+			return info, true
+		}
+	}
+	return KeyInfo{}, false
+}
@@ -0,0 +1,140 @@
+package pod
+
+import (
+	"regexp"
+	"sort"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestAnnotationCatalogConsistent(t *testing.T) {
+	seen := map[string]bool{}
+	for _, spec := range annotationCatalog {
+		assert.Assert(t, !seen[spec.Key], "duplicate catalog key: %s", spec.Key)
+		seen[spec.Key] = true
+
+		assert.Assert(t, spec.Type != "", "catalog key has no type: %s", spec.Key)
+		assert.Assert(t, spec.Component != "", "catalog key has no component: %s", spec.Key)
+		assert.Assert(t, spec.Description != "", "catalog key has no description: %s", spec.Key)
+		if spec.Replacement != "" {
+			assert.Assert(t, spec.Deprecated, "catalog key has a replacement but isn't deprecated: %s", spec.Key)
+		}
+
+		if spec.field == nil {
+			continue
+		}
+
+		if len(spec.AllowedValues) > 0 {
+			assert.Assert(t, spec.allowedDesc != "", "catalog key has allowed values but no description of them: %s", spec.Key)
+		}
+
+		var expType KeyType
+		switch spec.field(&Config{}).(type) {
+		case **string:
+			expType = KeyTypeString
+		case **bool:
+			expType = KeyTypeBool
+		case **uint32, **uint64, **int32:
+			expType = KeyTypeInteger
+		case **resource.Quantity:
+			expType = KeyTypeQuantity
+		case **time.Duration:
+			expType = KeyTypeDuration
+		case **regexp.Regexp:
+			expType = KeyTypeRegexp
+		case **[]string, *[]string:
+			expType = KeyTypeList
+		default:
+			t.Fatalf("catalog key has an unsupported field type: %s", spec.Key)
+		}
+
+		// Structured values are stored as plain strings
+		if spec.Type == KeyTypeStructured {
+			assert.Equal(t, expType, KeyTypeString, "catalog key has the wrong type: %s", spec.Key)
+		} else {
+			assert.Equal(t, expType, spec.Type, "catalog key has the wrong type: %s", spec.Key)
+		}
+	}
+}
+
+func TestKnownAnnotations(t *testing.T) {
+	known := KnownAnnotations()
+	assert.Equal(t, len(known), len(annotationCatalog))
+	assert.Assert(t, sort.SliceIsSorted(known, func(i, j int) bool {
+		return known[i].Key < known[j].Key
+	}))
+
+	legacy := map[string]string{
+		AnnotationKeySecurityGroupsLegacy: AnnotationKeyNetworkSecurityGroups,
+		AnnotationKeySubnetsLegacy:        AnnotationKeyNetworkSubnetIDs,
+		AnnotationKeyAccountIDLegacy:      AnnotationKeyNetworkAccountID,
+	}
+	for key, replacement := range legacy {
+		info, ok := LookupAnnotation(key)
+		assert.Assert(t, ok)
+		assert.Assert(t, info.Deprecated)
+		assert.Equal(t, info.Replacement, replacement)
+
+		_, ok = LookupAnnotation(replacement)
+		assert.Assert(t, ok)
+	}
+}
+
+func TestKnownAnnotationsCopied(t *testing.T) {
+	// Changing the returned allowed values doesn't change what the parser accepts
+	for _, info := range KnownAnnotations() {
+		for i := range info.AllowedValues {
+			info.AllowedValues[i] = "changed"
+		}
+	}
+	info, ok := LookupAnnotation(AnnotationKeyStorageEBSMountPerm)
+	assert.Assert(t, ok)
+	for i := range info.AllowedValues {
+		info.AllowedValues[i] = "changed"
+	}
+
+	assert.DeepEqual(t, ebsMountPerms, []string{EBSMountPermRO, EBSMountPermRW})
+	assert.DeepEqual(t, ebsFSTypes, []string{"ext3", "ext4", "xfs"})
+	info, ok = LookupAnnotation(AnnotationKeyPodSchedPolicy)
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, info.AllowedValues, []string{"batch", "idle"})
+}
+
+func TestLookupAnnotation(t *testing.T) {
+	info, ok := LookupAnnotation(AnnotationKeyPodSchedPolicy)
+	assert.Assert(t, ok)
+	assert.Equal(t, info.Type, KeyTypeString)
+	assert.Equal(t, info.Component, ComponentControlPlane)
+	assert.DeepEqual(t, info.AllowedValues, []string{"batch", "idle"})
+
+	info, ok = LookupAnnotation(AnnotationKeyServicePrefix + "/servicemesh.v2.image")
+	assert.Assert(t, ok)
+	assert.Equal(t, info.Key, AnnotationKeyServicePrefix)
+	assert.Assert(t, info.Prefix)
+
+	info, ok = LookupAnnotation(AnnotationKeyPrefixAppArmor + "/my-container")
+	assert.Assert(t, ok)
+	assert.Equal(t, info.Key, AnnotationKeyPrefixAppArmor)
+
+	_, ok = LookupAnnotation("pod.netflix.com/not-a-real-key")
+	assert.Assert(t, !ok)
+}
+
+func TestKnownLabels(t *testing.T) {
+	known := KnownLabels()
+	assert.Equal(t, len(known), len(labelCatalog))
+	for _, info := range known {
+		if info.Replacement != "" {
+			_, ok := LookupLabel(info.Replacement)
+			assert.Assert(t, ok, "label replacement isn't a known label: %s", info.Replacement)
+		}
+	}
+
+	info, ok := LookupLabel(LabelKeyAppLegacy)
+	assert.Assert(t, ok)
+	assert.Assert(t, info.Deprecated)
+	assert.Equal(t, info.Replacement, LabelKeyWorkloadName)
+}
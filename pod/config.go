@@ -14,12 +14,18 @@ import (
 // (such as annotations). All fields are pointers, to differentiate between a field being
 // unset and the empty value.
 type Config struct {
-	AssignIPv6Address        *bool
-	AccountID                *string
-	AppArmorProfile          *string
-	BytesEnabled             *bool
-	CapacityGroup            *string
+	AssignIPv6Address *bool
+	AccountID         *string
+	AppArmorProfile   *string
+	BytesEnabled      *bool
+	CapabilitiesAdd   []string
+	CapabilitiesDrop  []string
+	CapacityGroup     *string
+	// Containers contains the configuration of every container in the pod, including the
+	// workload container
+	Containers               []ContainerConfig
 	CPUBurstingEnabled       *bool
+	EBSVolume                *EBSVolume
 	ContainerInfo            *string
 	EgressBandwidth          *resource.Quantity
 	ElasticIPPool            *string
@@ -36,6 +42,7 @@ type Config struct {
 	JobType                  *string
 	JumboFramesEnabled       *bool
 	KvmEnabled               *bool
+	LegacyKeysUsed           []string
 	LogKeepLocalFile         *bool
 	LogUploadCheckInterval   *time.Duration
 	LogUploadThresholdTime   *time.Duration
@@ -47,18 +54,22 @@ type Config struct {
 	NetworkMode              *string
 	NetworkBurstingEnabled   *bool
 	OomScoreAdj              *int32
+	Opportunistic            *OpportunisticResources
 	PodSchemaVersion         *uint32
 	ResourceCPU              *resource.Quantity
 	ResourceDisk             *resource.Quantity
 	ResourceGPU              *resource.Quantity
 	ResourceMemory           *resource.Quantity
 	ResourceNetwork          *resource.Quantity
+	RuntimePrediction        *RuntimePrediction
 	SchedPolicy              *string
+	SeccompProfile           *corev1.SeccompProfile
 	SeccompAgentNetEnabled   *bool
 	SeccompAgentPerfEnabled  *bool
 	SecurityGroupIDs         *[]string
 	Sidecars                 []Sidecar
 	StaticIPAllocationUUID   *string
+	Sysctls                  map[string]string
 	SystemEnvVarNames        []string
 	SubnetIDs                *[]string
 	TaskID                   *string
@@ -77,9 +88,18 @@ type Sidecar struct {
 	Enabled bool
 	Image   string
 	Name    string
+	// Params are any other service configuration parameters, keyed by parameter name
+	Params  map[string]string
 	Version int
 }
 
+// OpportunisticResources represents the opportunistic resources the scheduler assigned to a pod,
+// on top of the resources it requested
+type OpportunisticResources struct {
+	CPU        *resource.Quantity
+	ResourceID *string
+}
+
 // PodToConfig pulls out values from a pod and turns them into a Config
 func PodToConfig(pod *corev1.Pod) (*Config, error) {
 	pConf := &Config{}
@@ -94,6 +114,8 @@ func PodToConfig(pod *corev1.Pod) (*Config, error) {
 		return pConf, err
 	}
 
+	parseLegacyKeys(pod, pConf)
+
 	err = parsePodFields(pod, pConf)
 	if err != nil {
 		return pConf, err
@@ -103,20 +125,24 @@ func PodToConfig(pod *corev1.Pod) (*Config, error) {
 }
 
 func getWorkloadContainer(pod *corev1.Pod, pconf *Config) *corev1.Container {
-	workloadContainer := pod.Spec.Containers[0]
+	if len(pod.Spec.Containers) == 0 {
+		return nil
+	}
+
+	workloadContainer := &pod.Spec.Containers[0]
 	if pconf.TaskID == nil {
-		return &workloadContainer
+		return workloadContainer
 	}
 
 	// Find the container named after the task ID
-	for _, c := range pod.Spec.Containers {
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
 		if c.Name == *pconf.TaskID {
-			ctrPtr := &c
-			return ctrPtr
+			return c
 		}
 	}
 
-	return &workloadContainer
+	return workloadContainer
 }
 
 func parsePodFields(pod *corev1.Pod, pConf *Config) error {
@@ -125,27 +151,86 @@ func parsePodFields(pod *corev1.Pod, pConf *Config) error {
 		return errors.New("could not find workload container in pod")
 	}
 
-	resources := workloadContainer.Resources.Limits
-	pConf.ResourceCPU = resourcePtr(resources, corev1.ResourceCPU)
-	pConf.ResourceDisk = resourcePtr(resources, corev1.ResourceEphemeralStorage)
-	pConf.ResourceGPU = resourcePtr(resources, resourceCommon.ResourceNameGpu)
-	pConf.ResourceMemory = resourcePtr(resources, corev1.ResourceMemory)
-	pConf.ResourceNetwork = resourcePtr(resources, resourceCommon.ResourceNameNetwork)
-	// XXX: do we need the legacy gpu and network resource names, too?
+	containers, err := parseContainers(pod, workloadContainer)
+	pConf.Containers = containers
+	if err != nil {
+		return err
+	}
+
+	// The pod's resources are the workload container's resources
+	for _, c := range containers {
+		if c.Role != ContainerRoleWorkload {
+			continue
+		}
+		pConf.ResourceCPU = c.Resources.CPU
+		pConf.ResourceDisk = c.Resources.Disk
+		pConf.ResourceGPU = c.Resources.GPU
+		pConf.ResourceMemory = c.Resources.Memory
+		pConf.ResourceNetwork = c.Resources.Network
+	}
 
 	if workloadContainer.TTY {
 		ttyEnabled := true
 		pConf.TTYEnabled = &ttyEnabled
 	}
 
+	parseSecurityContext(pod, workloadContainer, pConf)
 	return nil
 }
 
-func resourcePtr(resources corev1.ResourceList, resName corev1.ResourceName) *resource.Quantity {
-	res, ok := resources[resName]
-	if !ok {
+// EffectiveCPU returns the total number of CPUs available to the pod: the CPUs it requested,
+// plus any opportunistic CPUs assigned by the scheduler. If neither are set, returns nil.
+func (c *Config) EffectiveCPU() *resource.Quantity {
+	var opportunisticCPU *resource.Quantity
+	if c.Opportunistic != nil {
+		opportunisticCPU = c.Opportunistic.CPU
+	}
+
+	if c.ResourceCPU == nil && opportunisticCPU == nil {
+		return nil
+	}
+
+	total := resource.NewQuantity(0, resource.DecimalSI)
+	if c.ResourceCPU != nil {
+		total.Add(*c.ResourceCPU)
+	}
+	if opportunisticCPU != nil {
+		total.Add(*opportunisticCPU)
+	}
+
+	return total
+}
+
+func (c *Config) byteUnits() bool {
+	return c.BytesEnabled != nil && *c.BytesEnabled
+}
+
+// ResourceMemoryBytes returns the workload's memory limit in bytes, whether or not the control
+// plane sent it in byte units. Returns nil if the limit isn't set.
+func (c *Config) ResourceMemoryBytes() *resource.Quantity {
+	if c.ResourceMemory == nil {
 		return nil
 	}
+	q := resourceCommon.ToBytes(*c.ResourceMemory, c.byteUnits())
+	return &q
+}
 
-	return &res
+// ResourceDiskBytes returns the workload's disk limit in bytes, whether or not the control
+// plane sent it in byte units. Returns nil if the limit isn't set.
+func (c *Config) ResourceDiskBytes() *resource.Quantity {
+	if c.ResourceDisk == nil {
+		return nil
+	}
+	q := resourceCommon.ToBytes(*c.ResourceDisk, c.byteUnits())
+	return &q
+}
+
+// ResourceNetworkBitsPerSecond returns the workload's network bandwidth limit in bits/sec,
+// whether or not the control plane sent it in byte units. Returns nil if the limit isn't set.
+func (c *Config) ResourceNetworkBitsPerSecond() *resource.Quantity {
+	if c.ResourceNetwork == nil {
+		return nil
+	}
+	q := resourceCommon.ToBitsPerSecond(*c.ResourceNetwork, c.byteUnits())
+	return &q
 }
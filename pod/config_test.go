@@ -23,6 +23,11 @@ func stringToResourcePtr(val string) *resource.Quantity {
 	return &resVal
 }
 
+func intPtr(val int) *int {
+	ptrVal := &val
+	return ptrVal
+}
+
 func uint32Ptr(val uint32) *uint32 {
 	ptrVal := &val
 	return ptrVal
@@ -104,16 +109,15 @@ func TestParsePod(t *testing.T) {
 		AnnotationKeyNetworkSubnetIDs:              "subnet-1 , subnet-2 ",
 		AnnotationKeyPodTitusSystemEnvVarNames:     "SYSTEM1 , SYSTEM2 ",
 
-		// We don't parse these right now - including them so that
-		// tests fail if we do start parsing them or remove them
-		AnnotationKeyOpportunisticCPU:              "4",
-		AnnotationKeyOpportunisticResourceID:       "op-res-id",
-		AnnotationKeyPredictionRuntime:             "44",
-		AnnotationKeyPredictionConfidence:          "5",
+		AnnotationKeyOpportunisticCPU:        "4",
+		AnnotationKeyOpportunisticResourceID: "op-res-id",
+
+		AnnotationKeyPredictionRuntime:             "44s",
+		AnnotationKeyPredictionConfidence:          "0.5",
 		AnnotationKeyPredictionModelID:             "model-id",
 		AnnotationKeyPredictionModelVersion:        "v2",
 		AnnotationKeyPredictionABTestCell:          "cell1",
-		AnnotationKeyPredictionPredictionAvailable: "a,b",
+		AnnotationKeyPredictionPredictionAvailable: "0.5=44s;0.9=1m",
 		AnnotationKeyPredictionSelectorInfo:        "prediction",
 
 		AnnotationKeySecurityWorkloadMetadata:    "app-metadata",
@@ -169,19 +173,35 @@ func TestParsePod(t *testing.T) {
 	sgIDs := []string{"sg-1", "sg-2"}
 	subnetIDs := []string{"subnet-1", "subnet-2"}
 	expConf := Config{
-		AppArmorProfile:          ptr.StringPtr("localhost/docker_titus"),
-		AccountID:                ptr.StringPtr("123456"),
-		WorkloadDetail:           ptr.StringPtr("mydetail"),
-		WorkloadMetadata:         ptr.StringPtr("app-metadata"),
-		WorkloadMetadataSig:      ptr.StringPtr("app-metadata-sig"),
-		WorkloadName:             ptr.StringPtr("myapp"),
-		WorkloadOwnerEmail:       ptr.StringPtr("test@example.com"),
-		WorkloadSequence:         ptr.StringPtr("v000"),
-		WorkloadStack:            ptr.StringPtr("mystack"),
-		AssignIPv6Address:        ptr.BoolPtr(true),
-		BytesEnabled:             ptr.BoolPtr(true),
-		CapacityGroup:            ptr.StringPtr("DEFAULT"),
-		ContainerInfo:            ptr.StringPtr("cinfo"),
+		AppArmorProfile:     ptr.StringPtr("localhost/docker_titus"),
+		AccountID:           ptr.StringPtr("123456"),
+		WorkloadDetail:      ptr.StringPtr("mydetail"),
+		WorkloadMetadata:    ptr.StringPtr("app-metadata"),
+		WorkloadMetadataSig: ptr.StringPtr("app-metadata-sig"),
+		WorkloadName:        ptr.StringPtr("myapp"),
+		WorkloadOwnerEmail:  ptr.StringPtr("test@example.com"),
+		WorkloadSequence:    ptr.StringPtr("v000"),
+		WorkloadStack:       ptr.StringPtr("mystack"),
+		AssignIPv6Address:   ptr.BoolPtr(true),
+		BytesEnabled:        ptr.BoolPtr(true),
+		CapacityGroup:       ptr.StringPtr("DEFAULT"),
+		ContainerInfo:       ptr.StringPtr("cinfo"),
+		Containers: []ContainerConfig{
+			{
+				AppArmorProfile: ptr.StringPtr("localhost/docker_titus"),
+				Image:           "my-registry.example.com/sample/helloworld:latest",
+				Name:            taskId,
+				Resources: ContainerResources{
+					CPU:     stringToResourcePtr("1"),
+					Disk:    stringToResourcePtr("10737418240"),
+					GPU:     stringToResourcePtr("0"),
+					Memory:  stringToResourcePtr("536870912"),
+					Network: stringToResourcePtr("128M"),
+				},
+				Role:       ContainerRoleWorkload,
+				TTYEnabled: true,
+			},
+		},
 		CPUBurstingEnabled:       ptr.BoolPtr(true),
 		EgressBandwidth:          stringToResourcePtr("10M"),
 		ElasticIPPool:            ptr.StringPtr("pool-1"),
@@ -208,16 +228,32 @@ func TestParsePod(t *testing.T) {
 		NetworkMode:              ptr.StringPtr("example-network-mode"),
 		NetworkBurstingEnabled:   ptr.BoolPtr(true),
 		OomScoreAdj:              ptr.Int32Ptr(-800),
-		PodSchemaVersion:         uint32Ptr(2),
-		ResourceCPU:              stringToResourcePtr("1"),
-		ResourceDisk:             stringToResourcePtr("10737418240"),
-		ResourceMemory:           stringToResourcePtr("536870912"),
-		ResourceNetwork:          stringToResourcePtr("128M"),
-		ResourceGPU:              stringToResourcePtr("0"),
-		SchedPolicy:              ptr.StringPtr("batch"),
-		SeccompAgentNetEnabled:   ptr.BoolPtr(true),
-		SeccompAgentPerfEnabled:  ptr.BoolPtr(true),
-		SecurityGroupIDs:         &sgIDs,
+		Opportunistic: &OpportunisticResources{
+			CPU:        stringToResourcePtr("4"),
+			ResourceID: ptr.StringPtr("op-res-id"),
+		},
+		PodSchemaVersion: uint32Ptr(2),
+		ResourceCPU:      stringToResourcePtr("1"),
+		ResourceDisk:     stringToResourcePtr("10737418240"),
+		ResourceMemory:   stringToResourcePtr("536870912"),
+		ResourceNetwork:  stringToResourcePtr("128M"),
+		ResourceGPU:      stringToResourcePtr("0"),
+		RuntimePrediction: &RuntimePrediction{
+			Runtime:      durationPtr("44s"),
+			Confidence:   ptr.Float64Ptr(0.5),
+			ModelID:      ptr.StringPtr("model-id"),
+			ModelVersion: ptr.StringPtr("v2"),
+			ABTestCell:   ptr.StringPtr("cell1"),
+			Available: []PredictedRuntime{
+				{Confidence: 0.5, Runtime: 44 * time.Second},
+				{Confidence: 0.9, Runtime: time.Minute},
+			},
+			SelectorInfo: ptr.StringPtr("prediction"),
+		},
+		SchedPolicy:             ptr.StringPtr("batch"),
+		SeccompAgentNetEnabled:  ptr.BoolPtr(true),
+		SeccompAgentPerfEnabled: ptr.BoolPtr(true),
+		SecurityGroupIDs:        &sgIDs,
 		Sidecars: []Sidecar{
 			{Name: "servicemesh", Enabled: true, Image: "titusops/servicemesh:latest", Version: 2},
 		},
@@ -277,6 +313,68 @@ func TestParsePodInvalid(t *testing.T) {
 			},
 			errMatch: "annotation is not a valid duration value: " + AnnotationKeyLogStdioCheckInterval,
 		},
+		{
+			annotations: map[string]string{
+				AnnotationKeyOpportunisticCPU:        "four",
+				AnnotationKeyOpportunisticResourceID: "op-res-id",
+			},
+			errMatch: "annotation is not a valid resource value: " + AnnotationKeyOpportunisticCPU,
+		},
+		{
+			annotations: map[string]string{
+				AnnotationKeyOpportunisticCPU:        "1500m",
+				AnnotationKeyOpportunisticResourceID: "op-res-id",
+			},
+			errMatch: "annotation is not a valid opportunistic CPU count: " + AnnotationKeyOpportunisticCPU,
+		},
+		{
+			annotations: map[string]string{
+				AnnotationKeyOpportunisticCPU: "-2",
+			},
+			errMatch: "annotation is not a valid opportunistic CPU count: " + AnnotationKeyOpportunisticCPU,
+		},
+		{
+			annotations: map[string]string{
+				AnnotationKeyOpportunisticCPU: "2",
+			},
+			errMatch: "annotation must be set when " + AnnotationKeyOpportunisticCPU + " is set: " + AnnotationKeyOpportunisticResourceID,
+		},
+		{
+			annotations: map[string]string{
+				AnnotationKeyPredictionRuntime: "-5s",
+			},
+			errMatch: "annotation is not a valid duration value: " + AnnotationKeyPredictionRuntime,
+		},
+		{
+			annotations: map[string]string{
+				AnnotationKeyPredictionConfidence: "5",
+			},
+			errMatch: "annotation is not a valid confidence value: " + AnnotationKeyPredictionConfidence,
+		},
+		{
+			annotations: map[string]string{
+				AnnotationKeyPredictionConfidence: "NaN",
+			},
+			errMatch: "annotation is not a valid confidence value: " + AnnotationKeyPredictionConfidence,
+		},
+		{
+			annotations: map[string]string{
+				AnnotationKeyPredictionRuntime: "NaN",
+			},
+			errMatch: "annotation is not a valid duration value: " + AnnotationKeyPredictionRuntime,
+		},
+		{
+			annotations: map[string]string{
+				AnnotationKeyPredictionRuntime: "+Inf",
+			},
+			errMatch: "annotation is not a valid duration value: " + AnnotationKeyPredictionRuntime,
+		},
+		{
+			annotations: map[string]string{
+				AnnotationKeyPredictionPredictionAvailable: "a,b",
+			},
+			errMatch: "annotation is not a valid list of predictions: " + AnnotationKeyPredictionPredictionAvailable,
+		},
 		{
 			annotations: map[string]string{
 				AnnotationKeyPodSchedPolicy: "something",
@@ -307,6 +405,28 @@ func TestParsePodInvalid(t *testing.T) {
 			},
 			errMatch: "error parsing service image annotation: service.netflix.com/foo.v1.image: image does not have a digest or tag",
 		},
+		{
+			annotations: map[string]string{
+				AnnotationKeyServicePrefix + "/foo.v1.enabled": "true",
+			},
+			errMatch: "annotation must be set when the service is enabled: service.netflix.com/foo.v1.image",
+		},
+		{
+			annotations: map[string]string{
+				AnnotationKeyServicePrefix + "/foo.v1.enabled":  "false",
+				AnnotationKeyServicePrefix + "/foo.v01.enabled": "false",
+			},
+			errMatch: "annotation duplicates service version v01: service.netflix.com/foo.v1.enabled",
+		},
+		{
+			annotations: map[string]string{
+				AnnotationKeyServicePrefix + "/foo.v1.enabled": "true",
+				AnnotationKeyServicePrefix + "/foo.v1.image":   "titusops/foo:v1",
+				AnnotationKeyServicePrefix + "/foo.v2.enabled": "true",
+				AnnotationKeyServicePrefix + "/foo.v2.image":   "titusops/foo:v2",
+			},
+			errMatch: "annotation enables a second version of the service, after v1: service.netflix.com/foo.v2.enabled",
+		},
 	}
 
 	for _, ann := range badAnnotations {
@@ -358,6 +478,37 @@ func TestLogUploadRegExp(t *testing.T) {
 	assert.Equal(t, conf.LogUploadRegExp.String(), ".*.foo")
 }
 
+func TestEffectiveCPU(t *testing.T) {
+	pod := buildPod(map[string]string{
+		AnnotationKeyOpportunisticCPU:        "3",
+		AnnotationKeyOpportunisticResourceID: "op-res-id",
+	}, map[string]string{})
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.Equal(t, conf.EffectiveCPU().Value(), int64(4))
+
+	pod = buildPod(map[string]string{}, map[string]string{})
+	conf, err = PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.Assert(t, conf.Opportunistic == nil)
+	assert.Equal(t, conf.EffectiveCPU().Value(), int64(1))
+
+	assert.Assert(t, (&Config{}).EffectiveCPU() == nil)
+}
+
+func TestParseEmptyListAnnotation(t *testing.T) {
+	// An empty list annotation is a list with one empty entry, so that it's visible to validation
+	pod := buildPod(map[string]string{
+		AnnotationKeySecurityGroupsLegacy:      "",
+		AnnotationKeyPodTitusSystemEnvVarNames: "",
+	}, map[string]string{})
+
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, &[]string{""}, conf.SecurityGroupIDs)
+	assert.DeepEqual(t, []string{""}, conf.SystemEnvVarNames)
+}
+
 func TestServiceAnnotations(t *testing.T) {
 	imgWithSha := "titusops/svc@sha256:5abd793cc69018e747cb8d4bc288f1df7b20747f91ec26da88f0fa4ba2ec46a1"
 	annotations := map[string]string{
@@ -387,5 +538,86 @@ func TestServiceAnnotations(t *testing.T) {
 	}, sidecars)
 }
 
+func TestServiceAnnotationsParams(t *testing.T) {
+	annotations := map[string]string{
+		AnnotationKeyServicePrefix + "/zsvc.v1.enabled":     "false",
+		AnnotationKeyServicePrefix + "/svc.v10.enabled":     "true",
+		AnnotationKeyServicePrefix + "/svc.v10.image":       "titusops/svc:latest",
+		AnnotationKeyServicePrefix + "/svc.v10.port":        "8080",
+		AnnotationKeyServicePrefix + "/svc.v10.config-path": "/etc/svc",
+		AnnotationKeyServicePrefix + "/svc.v9.enabled":      "false",
+	}
+
+	pod := buildPod(annotations, map[string]string{})
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+
+	// A disabled version of a service (v9 here, say from before an upgrade) can be set alongside
+	// the enabled one. Sidecars are sorted by name, then numerically by version, so v9 comes
+	// before v10.
+	assert.DeepEqual(t, []Sidecar{
+		{Name: "svc", Enabled: false, Version: 9},
+		{
+			Name:    "svc",
+			Enabled: true,
+			Image:   "titusops/svc:latest",
+			Params: map[string]string{
+				"port":        "8080",
+				"config-path": "/etc/svc",
+			},
+			Version: 10,
+		},
+		{Name: "zsvc", Enabled: false, Version: 1},
+	}, conf.Sidecars)
+}
+
 // XXX: test all nil
-// XXX: test resources when bytes enabled
+
+func TestNormalizedResources(t *testing.T) {
+	pod := buildPod(map[string]string{}, map[string]string{})
+	pod.Spec.Containers[0].Resources.Limits = corev1.ResourceList{
+		corev1.ResourceMemory:              resource.MustParse("512"),
+		corev1.ResourceEphemeralStorage:    resource.MustParse("10240"),
+		resourceCommon.ResourceNameNetwork: resource.MustParse("128"),
+	}
+
+	// Without the byte units label, memory and disk are in MiB, and network is in Mbps
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.Equal(t, conf.ResourceMemoryBytes().String(), "512Mi")
+	assert.Equal(t, conf.ResourceDiskBytes().String(), "10Gi")
+	assert.Equal(t, conf.ResourceNetworkBitsPerSecond().String(), "128M")
+	// The raw values are left alone
+	assert.Equal(t, conf.ResourceMemory.String(), "512")
+
+	// Limits that already have a unit aren't scaled again
+	pod.Spec.Containers[0].Resources.Limits = corev1.ResourceList{
+		corev1.ResourceMemory:              resource.MustParse("512Mi"),
+		corev1.ResourceEphemeralStorage:    resource.MustParse("10Gi"),
+		resourceCommon.ResourceNameNetwork: resource.MustParse("128M"),
+	}
+
+	conf, err = PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.Equal(t, conf.ResourceMemoryBytes().String(), "512Mi")
+	assert.Equal(t, conf.ResourceDiskBytes().String(), "10Gi")
+	assert.Equal(t, conf.ResourceNetworkBitsPerSecond().String(), "128M")
+
+	pod.Labels = map[string]string{LabelKeyByteUnitsEnabled: "true"}
+	pod.Spec.Containers[0].Resources.Limits = corev1.ResourceList{
+		corev1.ResourceMemory:              resource.MustParse("536870912"),
+		corev1.ResourceEphemeralStorage:    resource.MustParse("10Gi"),
+		resourceCommon.ResourceNameNetwork: resource.MustParse("128M"),
+	}
+
+	conf, err = PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.Equal(t, conf.ResourceMemoryBytes().Value(), int64(512*1024*1024))
+	assert.Equal(t, conf.ResourceDiskBytes().String(), "10Gi")
+	assert.Equal(t, conf.ResourceNetworkBitsPerSecond().String(), "128M")
+
+	empty := &Config{}
+	assert.Assert(t, empty.ResourceMemoryBytes() == nil)
+	assert.Assert(t, empty.ResourceDiskBytes() == nil)
+	assert.Assert(t, empty.ResourceNetworkBitsPerSecond() == nil)
+}
@@ -0,0 +1,139 @@
+package pod
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// EnvKeyTaskID is the environment variable the task ID is passed to the workload in
+const EnvKeyTaskID = "TITUS_TASK_ID"
+
+// SourceKind is the kind of place in a pod a value is stored in
+type SourceKind string
+
+const (
+	SourceKindLabel         SourceKind = "label"
+	SourceKindAnnotation    SourceKind = "annotation"
+	SourceKindContainerName SourceKind = "container-name"
+	SourceKindEnv           SourceKind = "env"
+)
+
+// ValueSource is a place in a pod that a value is stored in
+type ValueSource struct {
+	Kind SourceKind
+	// Key is the label or annotation key, or the environment variable name. It's empty for
+	// container names.
+	Key string
+	// Container is the name of the container, for container names and environment variables
+	Container string
+	Value     string
+}
+
+func (s ValueSource) String() string {
+	switch s.Kind {
+	case SourceKindContainerName:
+		return "container name"
+	case SourceKindEnv:
+		return fmt.Sprintf("env var %s of container %s", s.Key, s.Container)
+	default:
+		return fmt.Sprintf("%s %s", s.Kind, s.Key)
+	}
+}
+
+// Inconsistency is a value that's duplicated in two places in a pod, where the two don't match
+type Inconsistency struct {
+	// Field describes the value, eg: "job ID"
+	Field  string
+	Source ValueSource
+	Other  ValueSource
+}
+
+func (i Inconsistency) Error() string {
+	return fmt.Sprintf("%s does not match %s: %q != %q", i.Source, i.Other, i.Source.Value, i.Other.Value)
+}
+
+// CheckConsistency compares the values that are duplicated between a pod's labels, annotations and
+// containers: the job ID and workload name, stack, detail and sequence labels and annotations,
+// and the task ID label, workload container name and its TITUS_TASK_ID environment variable. Only
+// values that are set in both places are compared.
+func CheckConsistency(pod *corev1.Pod) []Inconsistency {
+	labels := pod.GetLabels()
+	annotations := pod.GetAnnotations()
+	inconsistencies := []Inconsistency{}
+
+	check := func(field string, source, other ValueSource) {
+		if source.Value != other.Value {
+			inconsistencies = append(inconsistencies, Inconsistency{
+				Field:  field,
+				Source: source,
+				Other:  other,
+			})
+		}
+	}
+
+	duplicated := []struct {
+		field         string
+		labelKey      string
+		annotationKey string
+	}{
+		{
+			field:         "job ID",
+			labelKey:      LabelKeyJobId,
+			annotationKey: AnnotationKeyJobID,
+		},
+		{
+			field:         "workload name",
+			labelKey:      LabelKeyWorkloadName,
+			annotationKey: AnnotationKeyWorkloadName,
+		},
+		{
+			field:         "workload stack",
+			labelKey:      LabelKeyWorkloadStack,
+			annotationKey: AnnotationKeyWorkloadStack,
+		},
+		{
+			field:         "workload detail",
+			labelKey:      LabelKeyWorkloadDetail,
+			annotationKey: AnnotationKeyWorkloadDetail,
+		},
+		{
+			field:         "workload sequence",
+			labelKey:      LabelKeyWorkloadSequence,
+			annotationKey: AnnotationKeyWorkloadSequence,
+		},
+	}
+
+	for _, d := range duplicated {
+		labelVal, labelOk := labels[d.labelKey]
+		annotationVal, annotationOk := annotations[d.annotationKey]
+		if !labelOk || !annotationOk {
+			continue
+		}
+		check(d.field,
+			ValueSource{Kind: SourceKindLabel, Key: d.labelKey, Value: labelVal},
+			ValueSource{Kind: SourceKindAnnotation, Key: d.annotationKey, Value: annotationVal})
+	}
+
+	taskID, ok := labels[LabelKeyTaskId]
+	if !ok {
+		return inconsistencies
+	}
+	workloadContainer := getWorkloadContainer(pod, &Config{TaskID: &taskID})
+	if workloadContainer == nil {
+		return inconsistencies
+	}
+
+	taskIDSource := ValueSource{Kind: SourceKindLabel, Key: LabelKeyTaskId, Value: taskID}
+	check("task ID", taskIDSource,
+		ValueSource{Kind: SourceKindContainerName, Container: workloadContainer.Name, Value: workloadContainer.Name})
+
+	for _, env := range workloadContainer.Env {
+		if env.Name == EnvKeyTaskID && env.ValueFrom == nil {
+			check("task ID", taskIDSource,
+				ValueSource{Kind: SourceKindEnv, Key: EnvKeyTaskID, Container: workloadContainer.Name, Value: env.Value})
+		}
+	}
+
+	return inconsistencies
+}
@@ -0,0 +1,62 @@
+package pod
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestCheckConsistency(t *testing.T) {
+	pod, err := NewBuilder("task-1").
+		WithJob("job-1", "SERVICE").
+		WithWorkload("myapp", "prod", "", "v001").
+		Build()
+	assert.NilError(t, err)
+	pod.Spec.Containers[0].Env = []corev1.EnvVar{{Name: EnvKeyTaskID, Value: "task-1"}}
+
+	assert.DeepEqual(t, CheckConsistency(pod), []Inconsistency{})
+
+	pod.Labels[LabelKeyJobId] = "job-2"
+	pod.Labels[LabelKeyWorkloadStack] = "test"
+	pod.Spec.Containers[0].Env[0].Value = "task-2"
+
+	inconsistencies := CheckConsistency(pod)
+	assert.DeepEqual(t, inconsistencies, []Inconsistency{
+		{
+			Field:  "job ID",
+			Source: ValueSource{Kind: SourceKindLabel, Key: LabelKeyJobId, Value: "job-2"},
+			Other:  ValueSource{Kind: SourceKindAnnotation, Key: AnnotationKeyJobID, Value: "job-1"},
+		},
+		{
+			Field:  "workload stack",
+			Source: ValueSource{Kind: SourceKindLabel, Key: LabelKeyWorkloadStack, Value: "test"},
+			Other:  ValueSource{Kind: SourceKindAnnotation, Key: AnnotationKeyWorkloadStack, Value: "prod"},
+		},
+		{
+			Field:  "task ID",
+			Source: ValueSource{Kind: SourceKindLabel, Key: LabelKeyTaskId, Value: "task-1"},
+			Other:  ValueSource{Kind: SourceKindEnv, Key: EnvKeyTaskID, Container: "task-1", Value: "task-2"},
+		},
+	})
+	assert.Equal(t, inconsistencies[0].Error(), `label v3.job.titus.netflix.com/job-id does not match annotation v3.job.titus.netflix.com/id: "job-2" != "job-1"`)
+	assert.Equal(t, inconsistencies[2].Error(), `label v3.job.titus.netflix.com/task-id does not match env var TITUS_TASK_ID of container task-1: "task-1" != "task-2"`)
+}
+
+func TestCheckConsistencyContainerName(t *testing.T) {
+	pod := buildPod(map[string]string{}, map[string]string{
+		LabelKeyTaskId: "task-id-in-label",
+	})
+
+	assert.DeepEqual(t, CheckConsistency(pod), []Inconsistency{
+		{
+			Field:  "task ID",
+			Source: ValueSource{Kind: SourceKindLabel, Key: LabelKeyTaskId, Value: "task-id-in-label"},
+			Other:  ValueSource{Kind: SourceKindContainerName, Container: "task-id-in-container", Value: "task-id-in-container"},
+		},
+	})
+
+	// Values missing from one of the places aren't compared
+	delete(pod.Labels, LabelKeyTaskId)
+	assert.DeepEqual(t, CheckConsistency(pod), []Inconsistency{})
+}
@@ -1,7 +1,12 @@
 package pod
 
 import (
+	"fmt"
+
+	resourceCommon "github.com/Netflix/titus-kube-common/resource"
+	multierror "github.com/hashicorp/go-multierror"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 func GetUserContainer(pod *corev1.Pod) *corev1.Container {
@@ -30,3 +35,151 @@ func GetContainerByName(pod *corev1.Pod, name string) *corev1.Container {
 
 	return nil
 }
+
+// ContainerRole describes what a container in a pod is for
+type ContainerRole string
+
+const (
+	// ContainerRoleWorkload is the container running the Titus task itself
+	ContainerRoleWorkload ContainerRole = "workload"
+	// ContainerRolePlatformSidecar is a container run by the platform alongside the workload
+	ContainerRolePlatformSidecar ContainerRole = "platform-sidecar"
+	// ContainerRoleUser is any other container the user added to the pod
+	ContainerRoleUser ContainerRole = "user"
+)
+
+// ContainerResources contains the resource limits of a container, or the sum of them across a
+// pod. Limits that aren't set are nil.
+type ContainerResources struct {
+	CPU     *resource.Quantity
+	Disk    *resource.Quantity
+	GPU     *resource.Quantity
+	Memory  *resource.Quantity
+	Network *resource.Quantity
+}
+
+// ContainerConfig contains the configuration of a single container in a pod
+type ContainerConfig struct {
+	AppArmorProfile *string
+	Image           string
+	Name            string
+	Resources       ContainerResources
+	Role            ContainerRole
+	TTYEnabled      bool
+}
+
+// Build the per-container configs, in the order the containers appear in the pod spec
+func parseContainers(pod *corev1.Pod, workloadContainer *corev1.Container) ([]ContainerConfig, error) {
+	annotations := pod.GetAnnotations()
+	containers := []ContainerConfig{}
+	var err *multierror.Error
+
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		resources, rErr := containerResources(c)
+		if rErr != nil {
+			err = multierror.Append(err, rErr)
+		}
+
+		cConf := ContainerConfig{
+			Image:      c.Image,
+			Name:       c.Name,
+			Resources:  resources,
+			Role:       ContainerRoleUser,
+			TTYEnabled: c.TTY,
+		}
+
+		switch {
+		case c == workloadContainer:
+			cConf.Role = ContainerRoleWorkload
+		case IsPlatformSidecarContainer(c.Name, pod):
+			cConf.Role = ContainerRolePlatformSidecar
+		}
+
+		if appArmorVal, ok := annotations[AnnotationKeyPrefixAppArmor+"/"+c.Name]; ok {
+			cConf.AppArmorProfile = &appArmorVal
+		}
+
+		containers = append(containers, cConf)
+	}
+
+	return containers, err.ErrorOrNil()
+}
+
+// Read a container's resource limits, resolving the legacy resource names
+func containerResources(c *corev1.Container) (ContainerResources, error) {
+	cRes := ContainerResources{}
+	var err *multierror.Error
+
+	fields := []struct {
+		name  string
+		field **resource.Quantity
+	}{
+		{
+			name:  resourceCommon.ResourceNameCpu,
+			field: &cRes.CPU,
+		},
+		{
+			name:  resourceCommon.ResourceNameDisk,
+			field: &cRes.Disk,
+		},
+		{
+			name:  resourceCommon.ResourceNameGpu,
+			field: &cRes.GPU,
+		},
+		{
+			name:  resourceCommon.ResourceNameMemory,
+			field: &cRes.Memory,
+		},
+		{
+			name:  resourceCommon.ResourceNameNetwork,
+			field: &cRes.Network,
+		},
+	}
+
+	for _, f := range fields {
+		q, qErr := resourceCommon.GetQuantity(c.Resources.Limits, f.name)
+		if qErr != nil {
+			// Report the alias that conflicts, rather than the one that took precedence
+			key, value := f.name, ""
+			if cErr, ok := qErr.(*resourceCommon.ConflictError); ok {
+				key, value = cErr.Name, cErr.Value.String()
+			}
+			fErr := newFieldError(key, value, KeyTypeQuantity, fmt.Sprintf("container %s has invalid resources", c.Name))
+			fErr.Container = c.Name
+			fErr.Err = qErr
+			err = multierror.Append(err, fErr)
+			continue
+		}
+		*f.field = q
+	}
+
+	return cRes, err.ErrorOrNil()
+}
+
+// Add a quantity to a running total, leaving the total nil if neither are set
+func addQuantity(total, q *resource.Quantity) *resource.Quantity {
+	if q == nil {
+		return total
+	}
+	if total == nil {
+		sum := q.DeepCopy()
+		return &sum
+	}
+	total.Add(*q)
+	return total
+}
+
+// TotalResources returns the sum of the resource limits of every container in the pod,
+// including platform sidecars. Limits that no container sets are nil.
+func (c *Config) TotalResources() ContainerResources {
+	total := ContainerResources{}
+	for _, cConf := range c.Containers {
+		total.CPU = addQuantity(total.CPU, cConf.Resources.CPU)
+		total.Disk = addQuantity(total.Disk, cConf.Resources.Disk)
+		total.GPU = addQuantity(total.GPU, cConf.Resources.GPU)
+		total.Memory = addQuantity(total.Memory, cConf.Resources.Memory)
+		total.Network = addQuantity(total.Network, cConf.Resources.Network)
+	}
+	return total
+}
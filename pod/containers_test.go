@@ -0,0 +1,131 @@
+package pod
+
+import (
+	"testing"
+
+	resourceCommon "github.com/Netflix/titus-kube-common/resource"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	ptr "k8s.io/utils/pointer"
+)
+
+func TestMultiContainerConfig(t *testing.T) {
+	pod := buildPod(map[string]string{
+		AnnotationKeyPrefixAppArmor + "/sidecar":        "localhost/sidecar_profile",
+		AnnotationKeyPrefixContainerType + "sidecar":    AnnotationValueContainerTypePlatformSidecar,
+		AnnotationKeyPrefixAppArmor + "/not-in-the-pod": "localhost/unused",
+	}, map[string]string{
+		LabelKeyTaskId: "task-id-in-container",
+	})
+	pod.Spec.Containers = append(pod.Spec.Containers,
+		corev1.Container{
+			Name:  "sidecar",
+			Image: "titusops/sidecar:latest",
+			Resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:                 resource.MustParse("500m"),
+					corev1.ResourceMemory:              resource.MustParse("128Mi"),
+					resourceCommon.ResourceNameNetwork: resource.MustParse("64M"),
+				},
+			},
+		},
+		corev1.Container{
+			Name:  "user-sidecar",
+			Image: "myorg/helper:1.0",
+			TTY:   true,
+		},
+	)
+
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, []ContainerConfig{
+		{
+			Image: "my-registry.example.com/sample/helloworld:latest",
+			Name:  "task-id-in-container",
+			Resources: ContainerResources{
+				CPU:     stringToResourcePtr("1"),
+				Disk:    stringToResourcePtr("10Gi"),
+				GPU:     stringToResourcePtr("0"),
+				Memory:  stringToResourcePtr("512Mi"),
+				Network: stringToResourcePtr("128M"),
+			},
+			Role:       ContainerRoleWorkload,
+			TTYEnabled: true,
+		},
+		{
+			AppArmorProfile: ptr.StringPtr("localhost/sidecar_profile"),
+			Image:           "titusops/sidecar:latest",
+			Name:            "sidecar",
+			Resources: ContainerResources{
+				CPU:     stringToResourcePtr("500m"),
+				Memory:  stringToResourcePtr("128Mi"),
+				Network: stringToResourcePtr("64M"),
+			},
+			Role: ContainerRolePlatformSidecar,
+		},
+		{
+			Image:      "myorg/helper:1.0",
+			Name:       "user-sidecar",
+			Role:       ContainerRoleUser,
+			TTYEnabled: true,
+		},
+	}, conf.Containers)
+
+	// The pod-wide settings still come from the workload container
+	assert.Equal(t, conf.ResourceCPU.String(), "1")
+	assert.Assert(t, conf.AppArmorProfile == nil)
+
+	assert.DeepEqual(t, ContainerResources{
+		CPU:     stringToResourcePtr("1500m"),
+		Disk:    stringToResourcePtr("10Gi"),
+		GPU:     stringToResourcePtr("0"),
+		Memory:  stringToResourcePtr("640Mi"),
+		Network: stringToResourcePtr("192M"),
+	}, conf.TotalResources())
+
+	// Totals don't modify the per-container resources
+	assert.Equal(t, conf.Containers[0].Resources.CPU.String(), "1")
+	assert.DeepEqual(t, ContainerResources{}, (&Config{}).TotalResources())
+}
+
+func TestLegacyResourceNames(t *testing.T) {
+	pod := buildPod(map[string]string{}, map[string]string{})
+	pod.Spec.Containers[0].Resources.Limits = corev1.ResourceList{
+		corev1.ResourceCPU:                       resource.MustParse("2"),
+		resourceCommon.ResourceNameNvidiaGpu:     resource.MustParse("1"),
+		resourceCommon.ResourceNameNetworkLegacy: resource.MustParse("256M"),
+		resourceCommon.ResourceNameDiskLegacy:    resource.MustParse("20Gi"),
+	}
+
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.Equal(t, conf.ResourceGPU.String(), "1")
+	assert.Equal(t, conf.ResourceNetwork.String(), "256M")
+	assert.Equal(t, conf.ResourceDisk.String(), "20Gi")
+
+	// Writing the config back replaces the legacy names, but keeps nvidia.com/gpu, which the
+	// device plugin schedules on
+	conf.ResourceGPU = stringToResourcePtr("2")
+	assert.NilError(t, ApplyConfig(pod, conf))
+	limits := pod.Spec.Containers[0].Resources.Limits
+	nvidiaGpu := limits[resourceCommon.ResourceNameNvidiaGpu]
+	assert.Equal(t, nvidiaGpu.String(), "2")
+	gpu := limits[resourceCommon.ResourceNameGpu]
+	assert.Equal(t, gpu.String(), "2")
+	_, ok := limits[resourceCommon.ResourceNameNetworkLegacy]
+	assert.Assert(t, !ok)
+	_, ok = limits[resourceCommon.ResourceNameDiskLegacy]
+	assert.Assert(t, !ok)
+
+	pod.Spec.Containers[0].Resources.Limits[resourceCommon.ResourceNameGpuLegacy] = resource.MustParse("4")
+	_, err = PodToConfig(pod)
+	assert.ErrorContains(t, err, "container task-id-in-container has invalid resources: gpu: resource gpu conflicts with titus/gpu: 4 != 2")
+
+	fErrs := FieldErrors(err)
+	assert.Equal(t, len(fErrs), 1)
+	assert.Equal(t, fErrs[0].Key, resourceCommon.ResourceNameGpuLegacy)
+	assert.Equal(t, fErrs[0].Value, "4")
+	assert.Equal(t, fErrs[0].Container, "task-id-in-container")
+}
@@ -0,0 +1,160 @@
+package pod
+
+import (
+	"path"
+	"regexp"
+
+	multierror "github.com/hashicorp/go-multierror"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	EBSMountPermRO = "RO"
+	EBSMountPermRW = "RW"
+)
+
+var (
+	ebsVolumeIDRegexp = regexp.MustCompile(`^vol-([0-9a-f]{8}|[0-9a-f]{17})$`)
+	ebsFSTypes        = []string{"ext3", "ext4", "xfs"}
+	ebsMountPerms     = []string{EBSMountPermRO, EBSMountPermRW}
+)
+
+// EBSVolume represents an EBS volume that should be attached to the pod, and mounted
+// into the workload container
+type EBSVolume struct {
+	VolumeID  string
+	MountPath string
+	MountPerm string
+	FSType    string
+}
+
+// Parse the "ebs.volume.netflix.com/*" annotations. If any of them are set, they all must be.
+func parseEBSAnnotations(pod *corev1.Pod, userCtr *corev1.Container, pConf *Config) error {
+	annotations := pod.GetAnnotations()
+	var err *multierror.Error
+	found := false
+	vol := EBSVolume{}
+
+	stringAnnotations := []struct {
+		key   string
+		field *string
+	}{
+		{
+			key:   AnnotationKeyStorageEBSVolumeID,
+			field: &vol.VolumeID,
+		},
+		{
+			key:   AnnotationKeyStorageEBSMountPath,
+			field: &vol.MountPath,
+		},
+		{
+			key:   AnnotationKeyStorageEBSMountPerm,
+			field: &vol.MountPerm,
+		},
+		{
+			key:   AnnotationKeyStorageEBSFSType,
+			field: &vol.FSType,
+		},
+	}
+
+	for _, an := range stringAnnotations {
+		if val, ok := annotations[an.key]; ok {
+			found = true
+			*an.field = val
+		}
+	}
+
+	if !found {
+		return nil
+	}
+
+	for _, an := range stringAnnotations {
+		if _, ok := annotations[an.key]; !ok {
+			err = multierror.Append(err, newFieldError(an.key, "", KeyTypeString, "annotation must be set when attaching an EBS volume"))
+		}
+	}
+
+	if vol.VolumeID != "" && !ebsVolumeIDRegexp.MatchString(vol.VolumeID) {
+		err = multierror.Append(err, newFieldError(AnnotationKeyStorageEBSVolumeID, vol.VolumeID, KeyTypeString, "annotation is not a valid EBS volume ID"))
+	}
+
+	if vol.MountPath != "" && !path.IsAbs(vol.MountPath) {
+		err = multierror.Append(err, newFieldError(AnnotationKeyStorageEBSMountPath, vol.MountPath, KeyTypeString, "annotation is not an absolute path"))
+	}
+
+	if vol.MountPerm != "" && !containsString(ebsMountPerms, vol.MountPerm) {
+		err = multierror.Append(err, newFieldError(AnnotationKeyStorageEBSMountPerm, vol.MountPerm, KeyTypeString, "annotation is not a valid mount permission"))
+	}
+
+	if vol.FSType != "" && !containsString(ebsFSTypes, vol.FSType) {
+		err = multierror.Append(err, newFieldError(AnnotationKeyStorageEBSFSType, vol.FSType, KeyTypeString, "annotation is not a supported filesystem type"))
+	}
+
+	if vErr := checkEBSVolumeSpec(pod, userCtr, &vol); vErr != nil {
+		err = multierror.Append(err, vErr)
+	}
+
+	pConf.EBSVolume = &vol
+	return err.ErrorOrNil()
+}
+
+// Check that any awsElasticBlockStore volumes in the pod spec agree with the EBS annotations
+func checkEBSVolumeSpec(pod *corev1.Pod, userCtr *corev1.Container, vol *EBSVolume) error {
+	var err *multierror.Error
+
+	for _, v := range pod.Spec.Volumes {
+		ebs := v.AWSElasticBlockStore
+		if ebs == nil {
+			continue
+		}
+
+		if ebs.VolumeID != vol.VolumeID {
+			err = multierror.Append(err, newFieldError(AnnotationKeyStorageEBSVolumeID, vol.VolumeID, KeyTypeString, "annotation does not match the volume ID of pod volume "+v.Name))
+		}
+
+		if ebs.FSType != "" && ebs.FSType != vol.FSType {
+			err = multierror.Append(err, newFieldError(AnnotationKeyStorageEBSFSType, vol.FSType, KeyTypeString, "annotation does not match the filesystem type of pod volume "+v.Name))
+		}
+
+		if ebs.ReadOnly && vol.MountPerm == EBSMountPermRW {
+			err = multierror.Append(err, newFieldError(AnnotationKeyStorageEBSMountPerm, vol.MountPerm, KeyTypeString, "annotation does not match the read-only pod volume "+v.Name))
+		}
+
+		for _, vm := range userCtr.VolumeMounts {
+			if vm.Name != v.Name {
+				continue
+			}
+			if vm.MountPath != vol.MountPath {
+				err = multierror.Append(err, newFieldError(AnnotationKeyStorageEBSMountPath, vol.MountPath, KeyTypeString, "annotation does not match the mount path of pod volume "+v.Name))
+			}
+			if vm.ReadOnly && vol.MountPerm == EBSMountPermRW {
+				err = multierror.Append(err, newFieldError(AnnotationKeyStorageEBSMountPerm, vol.MountPerm, KeyTypeString, "annotation does not match the read-only mount of pod volume "+v.Name))
+			}
+		}
+	}
+
+	return err.ErrorOrNil()
+}
+
+// Generate the "ebs.volume.netflix.com/*" annotations
+func ebsVolumeToAnnotations(vol *EBSVolume) map[string]string {
+	if vol == nil {
+		return map[string]string{}
+	}
+
+	return map[string]string{
+		AnnotationKeyStorageEBSVolumeID:  vol.VolumeID,
+		AnnotationKeyStorageEBSMountPath: vol.MountPath,
+		AnnotationKeyStorageEBSMountPerm: vol.MountPerm,
+		AnnotationKeyStorageEBSFSType:    vol.FSType,
+	}
+}
+
+func containsString(list []string, val string) bool {
+	for _, v := range list {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package pod
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func ebsAnnotations() map[string]string {
+	return map[string]string{
+		AnnotationKeyStorageEBSVolumeID:  "vol-0123456789abcdef0",
+		AnnotationKeyStorageEBSMountPath: "/ebs",
+		AnnotationKeyStorageEBSMountPerm: EBSMountPermRO,
+		AnnotationKeyStorageEBSFSType:    "ext4",
+	}
+}
+
+func addEBSVolume(pod *corev1.Pod, volumeID, mountPath string, readOnly bool) {
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+		Name: "ebs-vol",
+		VolumeSource: corev1.VolumeSource{
+			AWSElasticBlockStore: &corev1.AWSElasticBlockStoreVolumeSource{
+				VolumeID: volumeID,
+				FSType:   "ext4",
+				ReadOnly: readOnly,
+			},
+		},
+	})
+	pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      "ebs-vol",
+		MountPath: mountPath,
+	})
+}
+
+func TestEBSVolume(t *testing.T) {
+	pod := buildPod(ebsAnnotations(), map[string]string{})
+	addEBSVolume(pod, "vol-0123456789abcdef0", "/ebs", true)
+
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, &EBSVolume{
+		VolumeID:  "vol-0123456789abcdef0",
+		MountPath: "/ebs",
+		MountPerm: EBSMountPermRO,
+		FSType:    "ext4",
+	}, conf.EBSVolume)
+}
+
+func TestEBSVolumeUnset(t *testing.T) {
+	pod := buildPod(map[string]string{}, map[string]string{})
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.Assert(t, conf.EBSVolume == nil)
+}
+
+func TestEBSVolumeInvalid(t *testing.T) {
+	badAnnotations := []struct {
+		key      string
+		val      string
+		errMatch string
+	}{
+		{
+			key:      AnnotationKeyStorageEBSVolumeID,
+			val:      "abcdef",
+			errMatch: "annotation is not a valid EBS volume ID: " + AnnotationKeyStorageEBSVolumeID,
+		},
+		{
+			key:      AnnotationKeyStorageEBSMountPath,
+			val:      "relative/path",
+			errMatch: "annotation is not an absolute path: " + AnnotationKeyStorageEBSMountPath,
+		},
+		{
+			key:      AnnotationKeyStorageEBSMountPerm,
+			val:      "WO",
+			errMatch: "annotation is not a valid mount permission: " + AnnotationKeyStorageEBSMountPerm,
+		},
+		{
+			key:      AnnotationKeyStorageEBSFSType,
+			val:      "zfs",
+			errMatch: "annotation is not a supported filesystem type: " + AnnotationKeyStorageEBSFSType,
+		},
+	}
+
+	for _, ba := range badAnnotations {
+		annotations := ebsAnnotations()
+		annotations[ba.key] = ba.val
+		_, err := PodToConfig(buildPod(annotations, map[string]string{}))
+		assert.ErrorContains(t, err, ba.errMatch)
+	}
+
+	annotations := ebsAnnotations()
+	delete(annotations, AnnotationKeyStorageEBSFSType)
+	_, err := PodToConfig(buildPod(annotations, map[string]string{}))
+	assert.ErrorContains(t, err, "annotation must be set when attaching an EBS volume: "+AnnotationKeyStorageEBSFSType)
+}
+
+func TestEBSVolumeSpecMismatch(t *testing.T) {
+	pod := buildPod(ebsAnnotations(), map[string]string{})
+	addEBSVolume(pod, "vol-0123456789abcdef1", "/ebs", false)
+	_, err := PodToConfig(pod)
+	assert.ErrorContains(t, err, "annotation does not match the volume ID of pod volume ebs-vol: "+AnnotationKeyStorageEBSVolumeID)
+
+	pod = buildPod(ebsAnnotations(), map[string]string{})
+	addEBSVolume(pod, "vol-0123456789abcdef0", "/mnt", false)
+	_, err = PodToConfig(pod)
+	assert.ErrorContains(t, err, "annotation does not match the mount path of pod volume ebs-vol: "+AnnotationKeyStorageEBSMountPath)
+
+	annotations := ebsAnnotations()
+	annotations[AnnotationKeyStorageEBSMountPerm] = EBSMountPermRW
+	pod = buildPod(annotations, map[string]string{})
+	addEBSVolume(pod, "vol-0123456789abcdef0", "/ebs", true)
+	_, err = PodToConfig(pod)
+	assert.ErrorContains(t, err, "annotation does not match the read-only pod volume ebs-vol: "+AnnotationKeyStorageEBSMountPerm)
+}
@@ -0,0 +1,75 @@
+package pod
+
+import (
+	"errors"
+	"fmt"
+
+	multierror "github.com/hashicorp/go-multierror"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// EnvConflict is a system environment variable that the user also set. Kubernetes uses the last
+// definition of a variable, so the user's value takes effect.
+type EnvConflict struct {
+	System corev1.EnvVar
+	User   corev1.EnvVar
+}
+
+// EnvSplit is the workload container's environment, split into the variables the system
+// provided, and the ones the user did
+type EnvSplit struct {
+	System    []corev1.EnvVar
+	User      []corev1.EnvVar
+	Conflicts []EnvConflict
+}
+
+// SplitEnv splits the workload container's environment variables into the system ones, named
+// in the system env var names annotation, and the user ones. The first definition of a system
+// variable is the system's, and any later definitions of it are user overrides, returned as
+// conflicts. It returns an error if a declared system variable isn't in the container, along
+// with the split environment.
+func SplitEnv(pod *corev1.Pod, conf *Config) (*EnvSplit, error) {
+	workloadContainer := getWorkloadContainer(pod, conf)
+	if workloadContainer == nil {
+		return nil, errors.New("could not find workload container in pod")
+	}
+
+	split := &EnvSplit{
+		System:    []corev1.EnvVar{},
+		User:      []corev1.EnvVar{},
+		Conflicts: []EnvConflict{},
+	}
+	systemVars := map[string]corev1.EnvVar{}
+
+	// This relies on the ordering the control plane uses: the Titus Job Coordinator writes the
+	// system variables first, then the user's variables after them (see the env section of
+	// docs/examples/complete-pod.yaml). If that ever changes, System and User will be swapped for
+	// any variable the user overrides.
+	for _, env := range workloadContainer.Env {
+		if !containsString(conf.SystemEnvVarNames, env.Name) {
+			split.User = append(split.User, env)
+			continue
+		}
+
+		if systemVar, ok := systemVars[env.Name]; ok {
+			split.User = append(split.User, env)
+			split.Conflicts = append(split.Conflicts, EnvConflict{
+				System: systemVar,
+				User:   env,
+			})
+			continue
+		}
+
+		systemVars[env.Name] = env
+		split.System = append(split.System, env)
+	}
+
+	var err *multierror.Error
+	for _, name := range conf.SystemEnvVarNames {
+		if _, ok := systemVars[name]; !ok {
+			err = multierror.Append(err, fmt.Errorf("system environment variable is not set in the workload container: %s", name))
+		}
+	}
+
+	return split, err.ErrorOrNil()
+}
@@ -0,0 +1,68 @@
+package pod
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestSplitEnv(t *testing.T) {
+	pod := buildPod(map[string]string{
+		AnnotationKeyPodTitusSystemEnvVarNames: "TITUS_TASK_ID,NETFLIX_EXECUTOR",
+	}, map[string]string{})
+	// The control plane puts the system variables before the user's, as in the example pod in
+	// docs/examples/complete-pod.yaml
+	pod.Spec.Containers[0].Env = []corev1.EnvVar{
+		{Name: "TITUS_TASK_ID", Value: "task-id-in-container"},
+		{Name: "NETFLIX_EXECUTOR", Value: "titus"},
+		{Name: "FOO", Value: "bar"},
+		{Name: "NETFLIX_EXECUTOR", Value: "mine"},
+	}
+
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+
+	split, err := SplitEnv(pod, conf)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, split, &EnvSplit{
+		System: []corev1.EnvVar{
+			{Name: "TITUS_TASK_ID", Value: "task-id-in-container"},
+			{Name: "NETFLIX_EXECUTOR", Value: "titus"},
+		},
+		User: []corev1.EnvVar{
+			{Name: "FOO", Value: "bar"},
+			{Name: "NETFLIX_EXECUTOR", Value: "mine"},
+		},
+		Conflicts: []EnvConflict{
+			{
+				System: corev1.EnvVar{Name: "NETFLIX_EXECUTOR", Value: "titus"},
+				User:   corev1.EnvVar{Name: "NETFLIX_EXECUTOR", Value: "mine"},
+			},
+		},
+	})
+}
+
+func TestSplitEnvMissingSystemVar(t *testing.T) {
+	pod := buildPod(map[string]string{
+		AnnotationKeyPodTitusSystemEnvVarNames: "TITUS_TASK_ID,NETFLIX_EXECUTOR",
+	}, map[string]string{})
+	pod.Spec.Containers[0].Env = []corev1.EnvVar{
+		{Name: "FOO", Value: "bar"},
+		{Name: "TITUS_TASK_ID", Value: "task-id-in-container"},
+	}
+
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+
+	split, err := SplitEnv(pod, conf)
+	assert.Error(t, err, "1 error occurred:\n\t* system environment variable is not set in the workload container: NETFLIX_EXECUTOR\n\n")
+	assert.Equal(t, len(split.System), 1)
+	assert.Equal(t, len(split.User), 1)
+
+	// Without the annotation, everything is user-provided
+	split, err = SplitEnv(pod, &Config{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(split.System), 0)
+	assert.Equal(t, len(split.User), 2)
+}
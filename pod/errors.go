@@ -0,0 +1,69 @@
+package pod
+
+import (
+	"errors"
+	"fmt"
+
+	multierror "github.com/hashicorp/go-multierror"
+)
+
+// FieldError is an error parsing the value of a single label, annotation, or container resource
+// limit or capability. Parse functions such as PodToConfig return them inside a multierror; use
+// FieldErrors to get at them.
+type FieldError struct {
+	Key   string
+	Value string
+	// Container is set if Key is a resource limit of the named container, or FieldKeyCapabilities,
+	// rather than a label or annotation
+	Container string
+	// ExpectedType is the type of value the key should have, if it has a well-defined type
+	ExpectedType KeyType
+	// Reason is a human-readable description of the problem, eg: "annotation is not a valid boolean value"
+	Reason string
+	// Err is the underlying error, if there is one
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %s", e.Reason, e.Key, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Reason, e.Key)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+func newFieldError(key, value string, expectedType KeyType, reason string) *FieldError {
+	return &FieldError{
+		Key:          key,
+		Value:        value,
+		ExpectedType: expectedType,
+		Reason:       reason,
+	}
+}
+
+// FieldErrors returns the FieldErrors contained in an error returned by one of the parse
+// functions, in the order they occurred. Other errors are skipped.
+func FieldErrors(err error) []*FieldError {
+	fieldErrs := []*FieldError{}
+	if err == nil {
+		return fieldErrs
+	}
+
+	errs := []error{err}
+	var mErr *multierror.Error
+	if errors.As(err, &mErr) {
+		errs = mErr.Errors
+	}
+
+	for _, e := range errs {
+		var fErr *FieldError
+		if errors.As(e, &fErr) {
+			fieldErrs = append(fieldErrs, fErr)
+		}
+	}
+
+	return fieldErrs
+}
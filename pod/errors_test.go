@@ -0,0 +1,67 @@
+package pod
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFieldErrors(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				AnnotationKeyPodCPUBurstingEnabled:                   "notabool",
+				AnnotationKeyLogUploadRegexp:                         "(unclosed",
+				AnnotationKeyServicePrefix + "/servicemesh.v1.image": "titusops/servicemesh",
+			},
+			Labels: map[string]string{
+				LabelKeyByteUnitsEnabled: "maybe",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "main"}},
+		},
+	}
+
+	_, err := PodToConfig(pod)
+	fieldErrs := FieldErrors(err)
+	assert.Equal(t, len(fieldErrs), 3)
+
+	assert.Equal(t, fieldErrs[0].Key, AnnotationKeyPodCPUBurstingEnabled)
+	assert.Equal(t, fieldErrs[0].Value, "notabool")
+	assert.Equal(t, fieldErrs[0].ExpectedType, KeyTypeBool)
+	assert.Equal(t, fieldErrs[0].Reason, "annotation is not a valid boolean value")
+	assert.Equal(t, fieldErrs[0].Error(), "annotation is not a valid boolean value: "+AnnotationKeyPodCPUBurstingEnabled)
+
+	assert.Equal(t, fieldErrs[1].Key, AnnotationKeyLogUploadRegexp)
+	assert.Equal(t, fieldErrs[1].ExpectedType, KeyTypeRegexp)
+	assert.Assert(t, fieldErrs[1].Err != nil)
+	assert.Assert(t, errors.Unwrap(fieldErrs[1]) == fieldErrs[1].Err)
+
+	assert.Equal(t, fieldErrs[2].Key, AnnotationKeyServicePrefix+"/servicemesh.v1.image")
+	assert.Equal(t, fieldErrs[2].Value, "titusops/servicemesh")
+	assert.Equal(t, fieldErrs[2].Error(), "error parsing service image annotation: service.netflix.com/servicemesh.v1.image: image does not have a digest or tag")
+
+	// Labels are only parsed once the annotations parse without errors
+	delete(pod.Annotations, AnnotationKeyPodCPUBurstingEnabled)
+	delete(pod.Annotations, AnnotationKeyLogUploadRegexp)
+	delete(pod.Annotations, AnnotationKeyServicePrefix+"/servicemesh.v1.image")
+	_, err = PodToConfig(pod)
+	fieldErrs = FieldErrors(err)
+	assert.Equal(t, len(fieldErrs), 1)
+	assert.Equal(t, fieldErrs[0].Key, LabelKeyByteUnitsEnabled)
+	assert.Equal(t, fieldErrs[0].Value, "maybe")
+}
+
+func TestFieldErrorsWrapped(t *testing.T) {
+	assert.Equal(t, len(FieldErrors(nil)), 0)
+	assert.Equal(t, len(FieldErrors(errors.New("some other error"))), 0)
+
+	fErr := newFieldError(AnnotationKeyPodOomScoreAdj, "x", KeyTypeInteger, "annotation is not a valid int32 value")
+	wrapped := fmt.Errorf("wrapped: %w", fErr)
+	assert.DeepEqual(t, FieldErrors(wrapped), []*FieldError{fErr})
+}
@@ -0,0 +1,70 @@
+package pod
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// HostnameStyleEC2 names the pod after its IP address, like an EC2 instance
+const HostnameStyleEC2 = "ec2"
+
+// ComputeHostname returns the hostname a pod's workload should have. By default, it's the task ID
+// (or the pod name, if the task ID isn't set). With the "ec2" hostname style, it's based on the
+// pod's assigned IP address, like EC2 instance hostnames: ip-10-0-0-1. The IPv4 address is used if
+// there is one, otherwise the IPv6 address is. The hostname must be a valid RFC 1123 DNS label.
+func ComputeHostname(pod *corev1.Pod, conf *Config) (string, error) {
+	style := ""
+	if conf.HostnameStyle != nil {
+		style = *conf.HostnameStyle
+	}
+
+	var hostname string
+	switch style {
+	case "":
+		hostname = pod.Name
+		if conf.TaskID != nil {
+			hostname = *conf.TaskID
+		}
+	case HostnameStyleEC2:
+		// Only the addresses are needed, so don't fail on unrelated network annotations
+		assignment := &NetworkAssignment{}
+		err := parseIPAddresses(pod.GetAnnotations(), assignment)
+		if err != nil {
+			return "", err
+		}
+		hostname, err = ec2Hostname(assignment)
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unknown hostname style: %q", style)
+	}
+
+	if errs := validation.IsDNS1123Label(hostname); len(errs) > 0 {
+		return "", fmt.Errorf("hostname is not a valid DNS label: %q: %s", hostname, strings.Join(errs, "; "))
+	}
+
+	return hostname, nil
+}
+
+func ec2Hostname(assignment *NetworkAssignment) (string, error) {
+	if ip := assignment.IPv4Address.To4(); ip != nil {
+		return "ip-" + strings.ReplaceAll(ip.String(), ".", "-"), nil
+	}
+
+	if ip := assignment.IPv6Address.To16(); ip != nil {
+		// Use the full form of the address, since the compressed form can start with "::"
+		groups := []string{}
+		for i := 0; i < net.IPv6len; i += 2 {
+			groups = append(groups, fmt.Sprintf("%02x%02x", ip[i], ip[i+1]))
+		}
+		return "ip-" + strings.Join(groups, "-"), nil
+	}
+
+	return "", errors.New("pod has no assigned IP address to use for an ec2 style hostname")
+}
@@ -0,0 +1,68 @@
+package pod
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+	ptr "k8s.io/utils/pointer"
+)
+
+func TestComputeHostnameDefault(t *testing.T) {
+	pod := buildPod(map[string]string{}, map[string]string{
+		LabelKeyTaskId: "46b59bd7-3d02-42c3-951e-cdbaa60f66e2",
+	})
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+
+	hostname, err := ComputeHostname(pod, conf)
+	assert.NilError(t, err)
+	assert.Equal(t, hostname, "46b59bd7-3d02-42c3-951e-cdbaa60f66e2")
+
+	// Falls back to the pod name
+	hostname, err = ComputeHostname(pod, &Config{})
+	assert.NilError(t, err)
+	assert.Equal(t, hostname, "foo")
+
+	_, err = ComputeHostname(pod, &Config{TaskID: ptr.StringPtr("Not_A_Label")})
+	assert.ErrorContains(t, err, `hostname is not a valid DNS label: "Not_A_Label"`)
+
+	_, err = ComputeHostname(pod, &Config{TaskID: ptr.StringPtr(strings.Repeat("a", 64))})
+	assert.ErrorContains(t, err, "hostname is not a valid DNS label")
+}
+
+func TestComputeHostnameEC2(t *testing.T) {
+	pod := buildPod(map[string]string{
+		AnnotationKeyPodHostnameStyle: HostnameStyleEC2,
+		AnnotationKeyIPv4Address:      "10.0.0.1",
+		AnnotationKeyIPv6Address:      "2600:1f18::1",
+	}, map[string]string{})
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+
+	hostname, err := ComputeHostname(pod, conf)
+	assert.NilError(t, err)
+	assert.Equal(t, hostname, "ip-10-0-0-1")
+
+	// Other network annotations aren't needed, so don't have to be valid
+	pod.Annotations[AnnotationKeyTrunkEniMac] = "not-a-mac"
+	hostname, err = ComputeHostname(pod, conf)
+	assert.NilError(t, err)
+	assert.Equal(t, hostname, "ip-10-0-0-1")
+
+	delete(pod.Annotations, AnnotationKeyIPv4Address)
+	hostname, err = ComputeHostname(pod, conf)
+	assert.NilError(t, err)
+	assert.Equal(t, hostname, "ip-2600-1f18-0000-0000-0000-0000-0000-0001")
+
+	delete(pod.Annotations, AnnotationKeyIPv6Address)
+	_, err = ComputeHostname(pod, conf)
+	assert.ErrorContains(t, err, "pod has no assigned IP address to use for an ec2 style hostname")
+
+	pod.Annotations[AnnotationKeyIPv4Address] = "not-an-ip"
+	_, err = ComputeHostname(pod, conf)
+	assert.ErrorContains(t, err, "annotation is not a valid IPv4 address: "+AnnotationKeyIPv4Address)
+
+	_, err = ComputeHostname(pod, &Config{HostnameStyle: ptr.StringPtr("fancy")})
+	assert.ErrorContains(t, err, `unknown hostname style: "fancy"`)
+}
@@ -0,0 +1,66 @@
+package pod
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// MaxJobDescriptorSize is the largest decompressed job descriptor that DecodeJobDescriptor
+// will accept, to protect against decompression bombs
+const MaxJobDescriptorSize = 10 * 1024 * 1024
+
+var ErrJobDescriptorTooLarge = fmt.Errorf("job descriptor is larger than %d bytes when decompressed", MaxJobDescriptorSize)
+
+// JobDescriptor is a decoded job descriptor annotation
+type JobDescriptor struct {
+	// Raw is the decompressed JSON job descriptor
+	Raw []byte
+	// Fields is a generic view of the job descriptor. Numbers are decoded as json.Number,
+	// so that large integers (such as timestamps) don't lose precision.
+	Fields map[string]interface{}
+}
+
+// DecodeJobDescriptor decodes the base64 encoded, gzipped job descriptor annotation
+func (c *Config) DecodeJobDescriptor() (*JobDescriptor, error) {
+	if c.JobDescriptor == nil {
+		return nil, errors.New("job descriptor annotation is not set: " + AnnotationKeyJobDescriptor)
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(*c.JobDescriptor)
+	if err != nil {
+		return nil, fmt.Errorf("job descriptor is not valid base64: %w", err)
+	}
+
+	gzReader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("job descriptor is not valid gzip data: %w", err)
+	}
+	defer gzReader.Close()
+
+	// Read one byte more than the limit, so we can tell if the limit was exceeded
+	raw, err := ioutil.ReadAll(io.LimitReader(gzReader, MaxJobDescriptorSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("job descriptor is not valid gzip data: %w", err)
+	}
+	if len(raw) > MaxJobDescriptorSize {
+		return nil, ErrJobDescriptorTooLarge
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	fields := map[string]interface{}{}
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("job descriptor is not a valid JSON object: %w", err)
+	}
+
+	return &JobDescriptor{
+		Raw:    raw,
+		Fields: fields,
+	}, nil
+}
@@ -0,0 +1,64 @@
+package pod
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func encodeJobDescriptor(t *testing.T, data []byte) *string {
+	var buf bytes.Buffer
+	gzWriter := gzip.NewWriter(&buf)
+	_, err := gzWriter.Write(data)
+	assert.NilError(t, err)
+	assert.NilError(t, gzWriter.Close())
+
+	encoded := base64.StdEncoding.EncodeToString(buf.Bytes())
+	return &encoded
+}
+
+func TestDecodeJobDescriptor(t *testing.T) {
+	raw := []byte(`{"owner":{"teamEmail":"test@example.com"},"attributes":{"foo":"bar"},"submittedAt":1602201163007}`)
+	conf := &Config{JobDescriptor: encodeJobDescriptor(t, raw)}
+
+	jd, err := conf.DecodeJobDescriptor()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, raw, jd.Raw)
+	assert.DeepEqual(t, map[string]interface{}{
+		"owner":       map[string]interface{}{"teamEmail": "test@example.com"},
+		"attributes":  map[string]interface{}{"foo": "bar"},
+		"submittedAt": json.Number("1602201163007"),
+	}, jd.Fields)
+}
+
+func TestDecodeJobDescriptorInvalid(t *testing.T) {
+	_, err := (&Config{}).DecodeJobDescriptor()
+	assert.ErrorContains(t, err, "job descriptor annotation is not set")
+
+	notBase64 := "not base64!"
+	_, err = (&Config{JobDescriptor: &notBase64}).DecodeJobDescriptor()
+	assert.ErrorContains(t, err, "job descriptor is not valid base64")
+
+	notGzip := base64.StdEncoding.EncodeToString([]byte("not gzip"))
+	_, err = (&Config{JobDescriptor: &notGzip}).DecodeJobDescriptor()
+	assert.ErrorContains(t, err, "job descriptor is not valid gzip data")
+
+	_, err = (&Config{JobDescriptor: encodeJobDescriptor(t, []byte("[1, 2]"))}).DecodeJobDescriptor()
+	assert.ErrorContains(t, err, "job descriptor is not a valid JSON object")
+}
+
+func TestDecodeJobDescriptorTooLarge(t *testing.T) {
+	bomb := make([]byte, MaxJobDescriptorSize+1)
+	_, err := (&Config{JobDescriptor: encodeJobDescriptor(t, bomb)}).DecodeJobDescriptor()
+	assert.Equal(t, err, ErrJobDescriptorTooLarge)
+
+	// Exactly at the limit is fine, as far as the size check goes
+	atLimit := bytes.Repeat([]byte(" "), MaxJobDescriptorSize-2)
+	atLimit = append([]byte("{"), append(atLimit, '}')...)
+	_, err = (&Config{JobDescriptor: encodeJobDescriptor(t, atLimit)}).DecodeJobDescriptor()
+	assert.NilError(t, err)
+}
@@ -1,7 +1,6 @@
 package pod
 
 import (
-	"fmt"
 	"strconv"
 
 	corev1 "k8s.io/api/core/v1"
@@ -61,7 +60,7 @@ func parseLabels(pod *corev1.Pod, pConf *Config) error {
 	if ok {
 		val, err := strconv.ParseBool(bytesEnabledStr)
 		if err != nil {
-			return fmt.Errorf("label is not a valid boolean value: %s", LabelKeyByteUnitsEnabled)
+			return newFieldError(LabelKeyByteUnitsEnabled, bytesEnabledStr, KeyTypeBool, "label is not a valid boolean value")
 		}
 		pConf.BytesEnabled = &val
 	}
@@ -0,0 +1,89 @@
+package pod
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Fill in values from the legacy (schema v0) labels and annotations. The v1 keys always take
+// precedence: a legacy key is only used when the corresponding v1 key is absent, regardless of
+// the pod schema version. The legacy keys that were used are recorded in the config.
+func parseLegacyKeys(pod *corev1.Pod, pConf *Config) {
+	labels := pod.GetLabels()
+	annotations := pod.GetAnnotations()
+	used := []string{}
+
+	stringLabels := []struct {
+		key   string
+		field **string
+	}{
+		{
+			key:   LabelKeyAppLegacy,
+			field: &pConf.WorkloadName,
+		},
+		{
+			key:   LabelKeyStackLegacy,
+			field: &pConf.WorkloadStack,
+		},
+		{
+			key:   LabelKeyDetailLegacy,
+			field: &pConf.WorkloadDetail,
+		},
+		{
+			key:   LabelKeySequenceLegacy,
+			field: &pConf.WorkloadSequence,
+		},
+		{
+			key:   LabelKeyCapacityGroupLegacy,
+			field: &pConf.CapacityGroup,
+		},
+	}
+
+	for _, l := range stringLabels {
+		if *l.field != nil {
+			continue
+		}
+		if val, ok := labels[l.key]; ok {
+			*l.field = &val
+			used = append(used, l.key)
+		}
+	}
+
+	if pConf.AccountID == nil {
+		if val, ok := annotations[AnnotationKeyAccountIDLegacy]; ok {
+			pConf.AccountID = &val
+			used = append(used, AnnotationKeyAccountIDLegacy)
+		}
+	}
+
+	listAnnotations := []struct {
+		key   string
+		field **[]string
+	}{
+		{
+			key:   AnnotationKeySecurityGroupsLegacy,
+			field: &pConf.SecurityGroupIDs,
+		},
+		{
+			key:   AnnotationKeySubnetsLegacy,
+			field: &pConf.SubnetIDs,
+		},
+	}
+
+	for _, an := range listAnnotations {
+		if *an.field != nil {
+			continue
+		}
+		if val, ok := annotations[an.key]; ok {
+			list := splitList(val)
+			*an.field = &list
+			used = append(used, an.key)
+		}
+	}
+
+	if len(used) > 0 {
+		sort.Strings(used)
+		pConf.LegacyKeysUsed = used
+	}
+}
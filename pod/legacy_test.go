@@ -0,0 +1,79 @@
+package pod
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	ptr "k8s.io/utils/pointer"
+)
+
+func TestLegacyKeys(t *testing.T) {
+	annotations := map[string]string{
+		AnnotationKeySecurityGroupsLegacy: "sg-1, sg-2",
+		AnnotationKeySubnetsLegacy:        "subnet-1,subnet-2",
+		AnnotationKeyAccountIDLegacy:      "123456",
+	}
+	labels := map[string]string{
+		LabelKeyAppLegacy:           "myapp",
+		LabelKeyStackLegacy:         "mystack",
+		LabelKeyDetailLegacy:        "mydetail",
+		LabelKeySequenceLegacy:      "v001",
+		LabelKeyCapacityGroupLegacy: "DEFAULT",
+	}
+
+	pod := buildPod(annotations, labels)
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, ptr.StringPtr("myapp"), conf.WorkloadName)
+	assert.DeepEqual(t, ptr.StringPtr("mystack"), conf.WorkloadStack)
+	assert.DeepEqual(t, ptr.StringPtr("mydetail"), conf.WorkloadDetail)
+	assert.DeepEqual(t, ptr.StringPtr("v001"), conf.WorkloadSequence)
+	assert.DeepEqual(t, ptr.StringPtr("DEFAULT"), conf.CapacityGroup)
+	assert.DeepEqual(t, ptr.StringPtr("123456"), conf.AccountID)
+	assert.DeepEqual(t, &[]string{"sg-1", "sg-2"}, conf.SecurityGroupIDs)
+	assert.DeepEqual(t, &[]string{"subnet-1", "subnet-2"}, conf.SubnetIDs)
+	assert.DeepEqual(t, []string{
+		LabelKeyAppLegacy,
+		LabelKeyDetailLegacy,
+		LabelKeySequenceLegacy,
+		LabelKeyStackLegacy,
+		AnnotationKeyAccountIDLegacy,
+		AnnotationKeySecurityGroupsLegacy,
+		AnnotationKeySubnetsLegacy,
+		LabelKeyCapacityGroupLegacy,
+	}, conf.LegacyKeysUsed)
+}
+
+func TestLegacyKeysPrecedence(t *testing.T) {
+	// v1 keys win over legacy keys, even on a schema v0 pod
+	annotations := map[string]string{
+		AnnotationKeyPodSchemaVersion:      "0",
+		AnnotationKeyWorkloadName:          "v1app",
+		AnnotationKeyNetworkSecurityGroups: "sg-v1",
+		AnnotationKeySecurityGroupsLegacy:  "sg-legacy",
+		AnnotationKeySubnetsLegacy:         "subnet-legacy",
+	}
+	labels := map[string]string{
+		LabelKeyAppLegacy:           "legacyapp",
+		LabelKeyCapacityGroup:       "v1-group",
+		LabelKeyCapacityGroupLegacy: "legacy-group",
+	}
+
+	pod := buildPod(annotations, labels)
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, ptr.StringPtr("v1app"), conf.WorkloadName)
+	assert.DeepEqual(t, ptr.StringPtr("v1-group"), conf.CapacityGroup)
+	assert.DeepEqual(t, &[]string{"sg-v1"}, conf.SecurityGroupIDs)
+	assert.DeepEqual(t, &[]string{"subnet-legacy"}, conf.SubnetIDs)
+	assert.DeepEqual(t, []string{AnnotationKeySubnetsLegacy}, conf.LegacyKeysUsed)
+}
+
+func TestNoLegacyKeys(t *testing.T) {
+	pod := buildPod(map[string]string{}, map[string]string{})
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.Assert(t, conf.LegacyKeysUsed == nil)
+}
@@ -0,0 +1,162 @@
+package pod
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	multierror "github.com/hashicorp/go-multierror"
+)
+
+const (
+	DefaultLogUploadThresholdTime = 6 * time.Hour
+	DefaultLogUploadCheckInterval = 15 * time.Minute
+	DefaultLogStdioCheckInterval  = 1 * time.Minute
+
+	// S3 keys can be at most 1024 bytes long
+	maxS3KeyLength = 1024
+)
+
+// LogUploadConfig is the configuration for uploading a workload's log files to S3, from the
+// "log.netflix.com/*" annotations, with defaults filled in
+type LogUploadConfig struct {
+	KeepLocalFile       bool
+	StdioCheckInterval  time.Duration
+	UploadCheckInterval time.Duration
+	UploadThresholdTime time.Duration
+	// UploadRegexp matches the log files to upload. If nil, the executor's default is used.
+	UploadRegexp *regexp.Regexp
+	// S3BucketName is the bucket to upload to. If empty, the executor's default bucket is used.
+	S3BucketName    string
+	S3PathPrefix    string
+	S3WriterIAMRole string
+}
+
+// LogUploadConfig returns the log upload configuration, with defaults filled in for any
+// annotations that aren't set. It returns an error if the configuration is invalid.
+func (c *Config) LogUploadConfig() (*LogUploadConfig, error) {
+	l := &LogUploadConfig{
+		StdioCheckInterval:  DefaultLogStdioCheckInterval,
+		UploadCheckInterval: DefaultLogUploadCheckInterval,
+		UploadThresholdTime: DefaultLogUploadThresholdTime,
+		UploadRegexp:        c.LogUploadRegExp,
+	}
+
+	if c.LogKeepLocalFile != nil {
+		l.KeepLocalFile = *c.LogKeepLocalFile
+	}
+	if c.LogStdioCheckInterval != nil {
+		l.StdioCheckInterval = *c.LogStdioCheckInterval
+	}
+	if c.LogUploadCheckInterval != nil {
+		l.UploadCheckInterval = *c.LogUploadCheckInterval
+	}
+	if c.LogUploadThresholdTime != nil {
+		l.UploadThresholdTime = *c.LogUploadThresholdTime
+	}
+	if c.LogS3BucketName != nil {
+		l.S3BucketName = *c.LogS3BucketName
+	}
+	if c.LogS3PathPrefix != nil {
+		l.S3PathPrefix = *c.LogS3PathPrefix
+	}
+	if c.LogS3WriterIAMRole != nil {
+		l.S3WriterIAMRole = *c.LogS3WriterIAMRole
+	}
+
+	// If only the threshold was set, blame it rather than the default check interval
+	checkIntervalSet := c.LogUploadCheckInterval != nil || c.LogUploadThresholdTime == nil
+	return l, l.validate(checkIntervalSet)
+}
+
+// Validate checks that the intervals are positive, that uploads are checked for more often than
+// the upload threshold, that a bucket is set if a writer role is, and that the path prefix is a
+// valid S3 key prefix
+func (l *LogUploadConfig) Validate() error {
+	return l.validate(true)
+}
+
+func (l *LogUploadConfig) validate(checkIntervalSet bool) error {
+	var err *multierror.Error
+
+	durations := []struct {
+		key string
+		val time.Duration
+	}{
+		{
+			key: AnnotationKeyLogStdioCheckInterval,
+			val: l.StdioCheckInterval,
+		},
+		{
+			key: AnnotationKeyLogUploadCheckInterval,
+			val: l.UploadCheckInterval,
+		},
+		{
+			key: AnnotationKeyLogUploadThresholdTime,
+			val: l.UploadThresholdTime,
+		},
+	}
+	for _, d := range durations {
+		if d.val <= 0 {
+			err = multierror.Append(err, newFieldError(d.key, d.val.String(), KeyTypeDuration, "annotation must be a positive duration"))
+		}
+	}
+
+	if l.UploadCheckInterval >= l.UploadThresholdTime {
+		if checkIntervalSet {
+			err = multierror.Append(err, newFieldError(AnnotationKeyLogUploadCheckInterval, l.UploadCheckInterval.String(), KeyTypeDuration,
+				"annotation must be shorter than "+AnnotationKeyLogUploadThresholdTime))
+		} else {
+			err = multierror.Append(err, newFieldError(AnnotationKeyLogUploadThresholdTime, l.UploadThresholdTime.String(), KeyTypeDuration,
+				"annotation must be longer than the default "+AnnotationKeyLogUploadCheckInterval+" of "+l.UploadCheckInterval.String()))
+		}
+	}
+
+	if l.S3WriterIAMRole != "" && l.S3BucketName == "" {
+		err = multierror.Append(err, newFieldError(AnnotationKeyLogS3BucketName, "", KeyTypeString,
+			"annotation must be set when "+AnnotationKeyLogS3WriterIAMRole+" is set"))
+	}
+
+	if l.S3PathPrefix != "" && !isValidS3KeyPrefix(l.S3PathPrefix) {
+		err = multierror.Append(err, newFieldError(AnnotationKeyLogS3PathPrefix, l.S3PathPrefix, KeyTypeString, "annotation is not a valid S3 key prefix"))
+	}
+
+	return err.ErrorOrNil()
+}
+
+// A valid prefix is UTF-8 without control characters, doesn't start with a slash, and doesn't
+// contain empty, "." or ".." path segments
+func isValidS3KeyPrefix(prefix string) bool {
+	if len(prefix) > maxS3KeyLength || !utf8.ValidString(prefix) || strings.HasPrefix(prefix, "/") {
+		return false
+	}
+
+	for _, r := range prefix {
+		if unicode.IsControl(r) {
+			return false
+		}
+	}
+
+	for _, segment := range strings.Split(strings.TrimSuffix(prefix, "/"), "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+
+	return true
+}
+
+// S3Key returns the key a task's log file is uploaded to: <prefix>/<task ID>/<file name>. The
+// file name may contain directories, but can't refer outside of the task's directory. It returns
+// an error if the task ID is empty, or would refer outside of (or to) the prefix.
+func (l *LogUploadConfig) S3Key(taskID, fileName string) (string, error) {
+	if taskID == "" || taskID == "." || taskID == ".." || strings.Contains(taskID, "/") {
+		return "", fmt.Errorf("task ID is not valid in an S3 key: %q", taskID)
+	}
+	cleanName := strings.TrimPrefix(path.Clean("/"+fileName), "/")
+	return path.Join(l.S3PathPrefix, taskID, cleanName), nil
+}
@@ -0,0 +1,114 @@
+package pod
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+	ptr "k8s.io/utils/pointer"
+)
+
+func TestLogUploadConfigDefaults(t *testing.T) {
+	l, err := (&Config{}).LogUploadConfig()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, l, &LogUploadConfig{
+		StdioCheckInterval:  DefaultLogStdioCheckInterval,
+		UploadCheckInterval: DefaultLogUploadCheckInterval,
+		UploadThresholdTime: DefaultLogUploadThresholdTime,
+	})
+}
+
+func TestLogUploadConfig(t *testing.T) {
+	pod := buildPod(map[string]string{
+		AnnotationKeyLogKeepLocalFile:       "true",
+		AnnotationKeyLogUploadCheckInterval: "5m",
+		AnnotationKeyLogS3BucketName:        "bucket-name",
+		AnnotationKeyLogS3PathPrefix:        "logs/titus/",
+		AnnotationKeyLogS3WriterIAMRole:     "arn:aws:iam::0:role/LogWriterRole",
+	}, map[string]string{})
+
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+	l, err := conf.LogUploadConfig()
+	assert.NilError(t, err)
+
+	assert.Equal(t, l.KeepLocalFile, true)
+	assert.Equal(t, l.UploadCheckInterval, 5*time.Minute)
+	assert.Equal(t, l.UploadThresholdTime, DefaultLogUploadThresholdTime)
+	assert.Equal(t, l.S3BucketName, "bucket-name")
+
+	keys := map[string]string{
+		"stdout":              "logs/titus/task-1/stdout",
+		"/logs/app.log":       "logs/titus/task-1/logs/app.log",
+		"../../task-2/stdout": "logs/titus/task-1/task-2/stdout",
+	}
+	for fileName, expected := range keys {
+		key, err := l.S3Key("task-1", fileName)
+		assert.NilError(t, err)
+		assert.Equal(t, key, expected)
+	}
+
+	l.S3PathPrefix = ""
+	key, err := l.S3Key("task-1", "stdout")
+	assert.NilError(t, err)
+	assert.Equal(t, key, "task-1/stdout")
+}
+
+func TestLogUploadS3KeyInvalidTaskID(t *testing.T) {
+	l := &LogUploadConfig{S3PathPrefix: "logs/titus"}
+	for _, taskID := range []string{"", ".", "..", "../task-2", "task-1/..", "/task-1"} {
+		_, err := l.S3Key(taskID, "stdout")
+		assert.ErrorContains(t, err, "task ID is not valid in an S3 key", taskID)
+	}
+}
+
+func TestLogUploadConfigInvalid(t *testing.T) {
+	badConfigs := []struct {
+		conf     Config
+		errMatch string
+	}{
+		{
+			conf:     Config{LogUploadCheckInterval: durationPtr("7h")},
+			errMatch: "annotation must be shorter than " + AnnotationKeyLogUploadThresholdTime + ": " + AnnotationKeyLogUploadCheckInterval,
+		},
+		{
+			conf:     Config{LogStdioCheckInterval: durationPtr("0s")},
+			errMatch: "annotation must be a positive duration: " + AnnotationKeyLogStdioCheckInterval,
+		},
+		{
+			conf:     Config{LogS3WriterIAMRole: ptr.StringPtr("arn:aws:iam::0:role/LogWriterRole")},
+			errMatch: "annotation must be set when " + AnnotationKeyLogS3WriterIAMRole + " is set: " + AnnotationKeyLogS3BucketName,
+		},
+		{
+			conf:     Config{LogS3PathPrefix: ptr.StringPtr("/logs")},
+			errMatch: "annotation is not a valid S3 key prefix: " + AnnotationKeyLogS3PathPrefix,
+		},
+		{
+			conf:     Config{LogS3PathPrefix: ptr.StringPtr("logs//titus")},
+			errMatch: "annotation is not a valid S3 key prefix: " + AnnotationKeyLogS3PathPrefix,
+		},
+		{
+			conf:     Config{LogS3PathPrefix: ptr.StringPtr("logs/../titus")},
+			errMatch: "annotation is not a valid S3 key prefix: " + AnnotationKeyLogS3PathPrefix,
+		},
+	}
+
+	for _, bc := range badConfigs {
+		_, err := bc.conf.LogUploadConfig()
+		assert.ErrorContains(t, err, bc.errMatch)
+	}
+
+	// Parsing a pod doesn't run the checks. If only the threshold is set, it's blamed rather than
+	// the default check interval.
+	pod := buildPod(map[string]string{
+		AnnotationKeyLogUploadThresholdTime: "10m",
+	}, map[string]string{})
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+
+	_, err = conf.LogUploadConfig()
+	fErrs := FieldErrors(err)
+	assert.Equal(t, len(fErrs), 1)
+	assert.Equal(t, fErrs[0].Key, AnnotationKeyLogUploadThresholdTime)
+	assert.Equal(t, fErrs[0].Reason, "annotation must be longer than the default "+AnnotationKeyLogUploadCheckInterval+" of 15m0s")
+}
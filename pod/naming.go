@@ -0,0 +1,163 @@
+package pod
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	multierror "github.com/hashicorp/go-multierror"
+)
+
+var (
+	// The characters allowed in app and stack names, which can't contain hyphens
+	monikerNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9._]+$`)
+	// The characters allowed in details, which can contain hyphens
+	monikerDetailRegexp   = regexp.MustCompile(`^[a-zA-Z0-9._~^-]+$`)
+	monikerSequenceRegexp = regexp.MustCompile(`^v[0-9]{3,6}$`)
+)
+
+// Moniker is a Frigga-style workload name, made up of the workload name (app), stack, detail and
+// sequence. They're composed into cluster names (app-stack-detail) and server group names
+// (app-stack-detail-v001). Only App is required.
+type Moniker struct {
+	App      string
+	Stack    string
+	Detail   string
+	Sequence string
+}
+
+// Moniker returns the workload's moniker, from the workload fields of the config. Since those
+// fall back to the legacy netflix.com/* labels, this works for legacy pods, too.
+func (c *Config) Moniker() Moniker {
+	m := Moniker{}
+	fields := []struct {
+		field *string
+		val   *string
+	}{
+		{
+			field: &m.App,
+			val:   c.WorkloadName,
+		},
+		{
+			field: &m.Stack,
+			val:   c.WorkloadStack,
+		},
+		{
+			field: &m.Detail,
+			val:   c.WorkloadDetail,
+		},
+		{
+			field: &m.Sequence,
+			val:   c.WorkloadSequence,
+		},
+	}
+
+	for _, f := range fields {
+		if f.val != nil {
+			*f.field = *f.val
+		}
+	}
+
+	return m
+}
+
+// MonikerFromLabels returns the moniker in a pod's labels. The v1 workload labels are used if
+// they're set, otherwise the legacy netflix.com/* labels are.
+func MonikerFromLabels(labels map[string]string) Moniker {
+	get := func(key, legacyKey string) string {
+		if val, ok := labels[key]; ok {
+			return val
+		}
+		return labels[legacyKey]
+	}
+
+	return Moniker{
+		App:      get(LabelKeyWorkloadName, LabelKeyAppLegacy),
+		Stack:    get(LabelKeyWorkloadStack, LabelKeyStackLegacy),
+		Detail:   get(LabelKeyWorkloadDetail, LabelKeyDetailLegacy),
+		Sequence: get(LabelKeyWorkloadSequence, LabelKeySequenceLegacy),
+	}
+}
+
+// Validate checks that each part of the moniker only uses the allowed characters, and that the
+// sequence is in the vNNN format
+func (m Moniker) Validate() error {
+	var err *multierror.Error
+
+	if m.App == "" {
+		err = multierror.Append(err, errors.New("workload name must be set"))
+	} else if !monikerNameRegexp.MatchString(m.App) {
+		err = multierror.Append(err, fmt.Errorf("workload name contains invalid characters: %q", m.App))
+	}
+
+	if m.Stack != "" && !monikerNameRegexp.MatchString(m.Stack) {
+		err = multierror.Append(err, fmt.Errorf("workload stack contains invalid characters: %q", m.Stack))
+	}
+
+	if m.Detail != "" && !monikerDetailRegexp.MatchString(m.Detail) {
+		err = multierror.Append(err, fmt.Errorf("workload detail contains invalid characters: %q", m.Detail))
+	}
+
+	if m.Sequence != "" && !monikerSequenceRegexp.MatchString(m.Sequence) {
+		err = multierror.Append(err, fmt.Errorf("workload sequence is not in the vNNN format: %q", m.Sequence))
+	}
+
+	return err.ErrorOrNil()
+}
+
+// ClusterName returns the cluster name: app-stack-detail. Trailing empty parts are left off, so
+// the cluster name may be just "app" or "app-stack", while an empty stack with a detail gives
+// "app--detail".
+func (m Moniker) ClusterName() string {
+	switch {
+	case m.Detail != "":
+		return m.App + "-" + m.Stack + "-" + m.Detail
+	case m.Stack != "":
+		return m.App + "-" + m.Stack
+	default:
+		return m.App
+	}
+}
+
+// ServerGroupName returns the server group name: the cluster name, followed by the sequence.
+// If the sequence isn't set, it's the same as the cluster name.
+func (m Moniker) ServerGroupName() string {
+	if m.Sequence == "" {
+		return m.ClusterName()
+	}
+	return m.ClusterName() + "-" + m.Sequence
+}
+
+// ParseClusterName splits a cluster name into its app, stack and detail, and validates them.
+// Everything after the second hyphen is the detail.
+func ParseClusterName(name string) (Moniker, error) {
+	m := Moniker{}
+	splitOut := strings.SplitN(name, "-", 3)
+	m.App = splitOut[0]
+	if len(splitOut) > 1 {
+		m.Stack = splitOut[1]
+	}
+	if len(splitOut) > 2 {
+		m.Detail = splitOut[2]
+	}
+
+	if err := m.Validate(); err != nil {
+		return m, err
+	}
+	return m, nil
+}
+
+// ParseServerGroupName splits a server group name into its app, stack, detail and sequence, and
+// validates them. A name without a trailing sequence is parsed as a cluster name.
+func ParseServerGroupName(name string) (Moniker, error) {
+	sequence := ""
+	if idx := strings.LastIndex(name, "-"); idx >= 0 && monikerSequenceRegexp.MatchString(name[idx+1:]) {
+		sequence = name[idx+1:]
+		name = name[:idx]
+	}
+
+	m, err := ParseClusterName(name)
+	m.Sequence = sequence
+	return m, err
+}
@@ -0,0 +1,115 @@
+package pod
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	ptr "k8s.io/utils/pointer"
+)
+
+func TestMonikerNames(t *testing.T) {
+	names := []struct {
+		moniker     Moniker
+		cluster     string
+		serverGroup string
+	}{
+		{
+			moniker:     Moniker{App: "myapp"},
+			cluster:     "myapp",
+			serverGroup: "myapp",
+		},
+		{
+			moniker:     Moniker{App: "myapp", Stack: "prod", Sequence: "v001"},
+			cluster:     "myapp-prod",
+			serverGroup: "myapp-prod-v001",
+		},
+		{
+			moniker:     Moniker{App: "myapp", Stack: "prod", Detail: "canary-a", Sequence: "v012"},
+			cluster:     "myapp-prod-canary-a",
+			serverGroup: "myapp-prod-canary-a-v012",
+		},
+		{
+			moniker:     Moniker{App: "myapp", Detail: "canary", Sequence: "v000"},
+			cluster:     "myapp--canary",
+			serverGroup: "myapp--canary-v000",
+		},
+	}
+
+	for _, n := range names {
+		assert.NilError(t, n.moniker.Validate())
+		assert.Equal(t, n.moniker.ClusterName(), n.cluster)
+		assert.Equal(t, n.moniker.ServerGroupName(), n.serverGroup)
+
+		parsed, err := ParseServerGroupName(n.serverGroup)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, parsed, n.moniker)
+
+		parsed, err = ParseClusterName(n.cluster)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, parsed, Moniker{App: n.moniker.App, Stack: n.moniker.Stack, Detail: n.moniker.Detail})
+	}
+}
+
+func TestMonikerInvalid(t *testing.T) {
+	badMonikers := []struct {
+		moniker  Moniker
+		errMatch string
+	}{
+		{
+			moniker:  Moniker{},
+			errMatch: "workload name must be set",
+		},
+		{
+			moniker:  Moniker{App: "my app"},
+			errMatch: `workload name contains invalid characters: "my app"`,
+		},
+		{
+			moniker:  Moniker{App: "myapp", Stack: "pr-od"},
+			errMatch: `workload stack contains invalid characters: "pr-od"`,
+		},
+		{
+			moniker:  Moniker{App: "myapp", Detail: "a/b"},
+			errMatch: `workload detail contains invalid characters: "a/b"`,
+		},
+		{
+			moniker:  Moniker{App: "myapp", Sequence: "v1"},
+			errMatch: `workload sequence is not in the vNNN format: "v1"`,
+		},
+	}
+
+	for _, bm := range badMonikers {
+		assert.ErrorContains(t, bm.moniker.Validate(), bm.errMatch)
+	}
+
+	_, err := ParseServerGroupName("my app-prod-v001")
+	assert.ErrorContains(t, err, `workload name contains invalid characters: "my app"`)
+
+	// A malformed sequence is treated as part of the detail
+	m, err := ParseServerGroupName("myapp-prod-v1")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, m, Moniker{App: "myapp", Stack: "prod", Detail: "v1"})
+}
+
+func TestMonikerFromConfigAndLabels(t *testing.T) {
+	conf := &Config{
+		WorkloadName:     ptr.StringPtr("myapp"),
+		WorkloadStack:    ptr.StringPtr("prod"),
+		WorkloadSequence: ptr.StringPtr("v003"),
+	}
+	assert.Equal(t, conf.Moniker().ServerGroupName(), "myapp-prod-v003")
+
+	legacy := map[string]string{
+		LabelKeyAppLegacy:      "legacyapp",
+		LabelKeyStackLegacy:    "test",
+		LabelKeySequenceLegacy: "v002",
+		LabelKeyDetailLegacy:   "old",
+		LabelKeyWorkloadDetail: "new",
+	}
+	assert.DeepEqual(t, MonikerFromLabels(legacy), Moniker{App: "legacyapp", Stack: "test", Detail: "new", Sequence: "v002"})
+
+	delete(legacy, LabelKeyWorkloadDetail)
+	pod := buildPod(map[string]string{}, legacy)
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.Equal(t, conf.Moniker().ServerGroupName(), "legacyapp-test-old-v002")
+}
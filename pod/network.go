@@ -0,0 +1,278 @@
+package pod
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+
+	multierror "github.com/hashicorp/go-multierror"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	maxVlanID = 4094
+)
+
+// ENI represents an elastic network interface that a pod's network is attached to
+type ENI struct {
+	ID       string
+	MAC      net.HardwareAddr
+	VpcID    string
+	SubnetID string
+}
+
+// NetworkAssignment represents the network resources that the Titus CNI assigned to a pod.
+// All fields are optional, since the CNI may only assign some of them (eg: no IPv6 address).
+type NetworkAssignment struct {
+	IPv4Address      net.IP
+	IPv4PrefixLength *int
+	IPv6Address      net.IP
+	IPv6PrefixLength *int
+	// BranchENI is the ENI the pod's traffic is sent over
+	BranchENI *ENI
+	// TrunkENI is the ENI on the node that the branch ENI is attached to. Its subnet isn't recorded.
+	TrunkENI        *ENI
+	VlanID          *int
+	AllocationIndex *uint16
+}
+
+// GetNetworkAssignment parses the network assignment annotations written by the Titus CNI. As
+// with PodToConfig, the fields that could be parsed are returned alongside any errors.
+func GetNetworkAssignment(pod *corev1.Pod) (*NetworkAssignment, error) {
+	annotations := pod.GetAnnotations()
+	assignment := &NetworkAssignment{}
+	var err *multierror.Error
+
+	if aErr := parseIPAddresses(annotations, assignment); aErr != nil {
+		err = multierror.Append(err, aErr)
+	}
+
+	prefixAnnotations := []struct {
+		key   string
+		max   int
+		field **int
+	}{
+		{
+			key:   AnnotationKeyIPv4PrefixLength,
+			max:   net.IPv4len * 8,
+			field: &assignment.IPv4PrefixLength,
+		},
+		{
+			key:   AnnotationKeyIPv6PrefixLength,
+			max:   net.IPv6len * 8,
+			field: &assignment.IPv6PrefixLength,
+		},
+	}
+
+	for _, an := range prefixAnnotations {
+		val, ok := annotations[an.key]
+		if !ok {
+			continue
+		}
+		prefixLen, pErr := strconv.Atoi(val)
+		if pErr != nil || prefixLen < 0 || prefixLen > an.max {
+			err = multierror.Append(err, newFieldError(an.key, val, KeyTypeInteger, "annotation is not a valid prefix length"))
+			continue
+		}
+		*an.field = &prefixLen
+	}
+
+	branchENI, eErr := parseENIAnnotations(annotations, AnnotationKeyBranchEniID, AnnotationKeyBranchEniMac, AnnotationKeyBranchEniVpcID, AnnotationKeyBranchEniSubnet)
+	if eErr != nil {
+		err = multierror.Append(err, eErr)
+	}
+	assignment.BranchENI = branchENI
+
+	trunkENI, eErr := parseENIAnnotations(annotations, AnnotationKeyTrunkEniID, AnnotationKeyTrunkEniMac, AnnotationKeyTrunkEniVpcID, "")
+	if eErr != nil {
+		err = multierror.Append(err, eErr)
+	}
+	assignment.TrunkENI = trunkENI
+
+	if val, ok := annotations[AnnotationKeyVlanID]; ok {
+		vlanID, pErr := strconv.Atoi(val)
+		if pErr != nil || vlanID < 1 || vlanID > maxVlanID {
+			err = multierror.Append(err, newFieldError(AnnotationKeyVlanID, val, KeyTypeInteger, "annotation is not a valid VLAN ID"))
+		} else {
+			assignment.VlanID = &vlanID
+		}
+	}
+
+	if val, ok := annotations[AnnotationKeyAllocationIdx]; ok {
+		parsedVal, pErr := strconv.ParseUint(val, 10, 16)
+		if pErr != nil {
+			err = multierror.Append(err, newFieldError(AnnotationKeyAllocationIdx, val, KeyTypeInteger, "annotation is not a valid uint16 value"))
+		} else {
+			allocIdx := uint16(parsedVal)
+			assignment.AllocationIndex = &allocIdx
+		}
+	}
+
+	return assignment, err.ErrorOrNil()
+}
+
+// Parse the IPv4 and IPv6 address annotations into an assignment
+func parseIPAddresses(annotations map[string]string, assignment *NetworkAssignment) error {
+	var err *multierror.Error
+
+	if val, ok := annotations[AnnotationKeyIPv4Address]; ok {
+		ip := net.ParseIP(val)
+		if ip == nil || ip.To4() == nil {
+			err = multierror.Append(err, newFieldError(AnnotationKeyIPv4Address, val, KeyTypeIPAddress, "annotation is not a valid IPv4 address"))
+		} else {
+			assignment.IPv4Address = ip.To4()
+		}
+	}
+
+	if val, ok := annotations[AnnotationKeyIPv6Address]; ok {
+		ip := net.ParseIP(val)
+		if ip == nil || ip.To4() != nil {
+			err = multierror.Append(err, newFieldError(AnnotationKeyIPv6Address, val, KeyTypeIPAddress, "annotation is not a valid IPv6 address"))
+		} else {
+			assignment.IPv6Address = ip
+		}
+	}
+
+	return err.ErrorOrNil()
+}
+
+// Parse the annotations for a single ENI. Returns nil if none of them are set.
+func parseENIAnnotations(annotations map[string]string, idKey, macKey, vpcKey, subnetKey string) (*ENI, error) {
+	eni := &ENI{}
+	found := false
+
+	stringAnnotations := []struct {
+		key   string
+		field *string
+	}{
+		{
+			key:   idKey,
+			field: &eni.ID,
+		},
+		{
+			key:   vpcKey,
+			field: &eni.VpcID,
+		},
+		{
+			key:   subnetKey,
+			field: &eni.SubnetID,
+		},
+	}
+
+	for _, an := range stringAnnotations {
+		if an.key == "" {
+			continue
+		}
+		if val, ok := annotations[an.key]; ok {
+			found = true
+			*an.field = val
+		}
+	}
+
+	var err error
+	if val, ok := annotations[macKey]; ok {
+		found = true
+		mac, pErr := net.ParseMAC(val)
+		if pErr != nil || len(mac) != 6 {
+			err = newFieldError(macKey, val, KeyTypeMACAddress, "annotation is not a valid MAC address")
+		} else {
+			eni.MAC = mac
+		}
+	}
+
+	if !found {
+		return nil, err
+	}
+	return eni, err
+}
+
+func (a *NetworkAssignment) validate() error {
+	var err *multierror.Error
+
+	if a.IPv4Address != nil && a.IPv4Address.To4() == nil {
+		err = multierror.Append(err, fmt.Errorf("IPv4 address is not valid: %s", a.IPv4Address))
+	}
+	if a.IPv6Address != nil && (len(a.IPv6Address) != net.IPv6len || a.IPv6Address.To4() != nil) {
+		err = multierror.Append(err, fmt.Errorf("IPv6 address is not valid: %s", a.IPv6Address))
+	}
+	if a.IPv4PrefixLength != nil && (*a.IPv4PrefixLength < 0 || *a.IPv4PrefixLength > net.IPv4len*8) {
+		err = multierror.Append(err, fmt.Errorf("IPv4 prefix length is not valid: %d", *a.IPv4PrefixLength))
+	}
+	if a.IPv6PrefixLength != nil && (*a.IPv6PrefixLength < 0 || *a.IPv6PrefixLength > net.IPv6len*8) {
+		err = multierror.Append(err, fmt.Errorf("IPv6 prefix length is not valid: %d", *a.IPv6PrefixLength))
+	}
+	if a.BranchENI != nil && a.BranchENI.MAC != nil && len(a.BranchENI.MAC) != 6 {
+		err = multierror.Append(err, fmt.Errorf("branch ENI MAC address is not valid: %s", a.BranchENI.MAC))
+	}
+	if a.TrunkENI != nil {
+		if a.TrunkENI.MAC != nil && len(a.TrunkENI.MAC) != 6 {
+			err = multierror.Append(err, fmt.Errorf("trunk ENI MAC address is not valid: %s", a.TrunkENI.MAC))
+		}
+		if a.TrunkENI.SubnetID != "" {
+			err = multierror.Append(err, errors.New("trunk ENI subnet can not be stored on a pod"))
+		}
+	}
+	if a.VlanID != nil && (*a.VlanID < 1 || *a.VlanID > maxVlanID) {
+		err = multierror.Append(err, fmt.Errorf("VLAN ID is not valid: %d", *a.VlanID))
+	}
+
+	return err.ErrorOrNil()
+}
+
+// SetNetworkAssignment writes a network assignment onto a pod's annotations. Unset (nil) fields
+// are left untouched. If the assignment is invalid, the pod isn't modified.
+func SetNetworkAssignment(pod *corev1.Pod, a *NetworkAssignment) error {
+	if err := a.validate(); err != nil {
+		return err
+	}
+
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	annotations := pod.Annotations
+
+	if a.IPv4Address != nil {
+		annotations[AnnotationKeyIPv4Address] = a.IPv4Address.String()
+	}
+	if a.IPv4PrefixLength != nil {
+		annotations[AnnotationKeyIPv4PrefixLength] = strconv.Itoa(*a.IPv4PrefixLength)
+	}
+	if a.IPv6Address != nil {
+		annotations[AnnotationKeyIPv6Address] = a.IPv6Address.String()
+	}
+	if a.IPv6PrefixLength != nil {
+		annotations[AnnotationKeyIPv6PrefixLength] = strconv.Itoa(*a.IPv6PrefixLength)
+	}
+
+	setENIAnnotations(annotations, a.BranchENI, AnnotationKeyBranchEniID, AnnotationKeyBranchEniMac, AnnotationKeyBranchEniVpcID, AnnotationKeyBranchEniSubnet)
+	setENIAnnotations(annotations, a.TrunkENI, AnnotationKeyTrunkEniID, AnnotationKeyTrunkEniMac, AnnotationKeyTrunkEniVpcID, "")
+
+	if a.VlanID != nil {
+		annotations[AnnotationKeyVlanID] = strconv.Itoa(*a.VlanID)
+	}
+	if a.AllocationIndex != nil {
+		annotations[AnnotationKeyAllocationIdx] = strconv.FormatUint(uint64(*a.AllocationIndex), 10)
+	}
+
+	return nil
+}
+
+func setENIAnnotations(annotations map[string]string, eni *ENI, idKey, macKey, vpcKey, subnetKey string) {
+	if eni == nil {
+		return
+	}
+
+	if eni.ID != "" {
+		annotations[idKey] = eni.ID
+	}
+	if eni.MAC != nil {
+		annotations[macKey] = eni.MAC.String()
+	}
+	if eni.VpcID != "" {
+		annotations[vpcKey] = eni.VpcID
+	}
+	if subnetKey != "" && eni.SubnetID != "" {
+		annotations[subnetKey] = eni.SubnetID
+	}
+}
@@ -0,0 +1,150 @@
+package pod
+
+import (
+	"net"
+	"testing"
+
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func networkAnnotations() map[string]string {
+	return map[string]string{
+		AnnotationKeyIPv4Address:      "10.0.0.1",
+		AnnotationKeyIPv4PrefixLength: "24",
+		AnnotationKeyIPv6Address:      "2600:1f18::1",
+		AnnotationKeyIPv6PrefixLength: "128",
+
+		AnnotationKeyBranchEniID:     "eni-branch",
+		AnnotationKeyBranchEniMac:    "0a:1b:2c:3d:4e:5f",
+		AnnotationKeyBranchEniVpcID:  "vpc-1",
+		AnnotationKeyBranchEniSubnet: "subnet-1",
+
+		AnnotationKeyTrunkEniID:    "eni-trunk",
+		AnnotationKeyTrunkEniMac:   "0a:1b:2c:3d:4e:60",
+		AnnotationKeyTrunkEniVpcID: "vpc-1",
+
+		AnnotationKeyVlanID:        "3",
+		AnnotationKeyAllocationIdx: "7",
+	}
+}
+
+func mustParseMAC(val string) net.HardwareAddr {
+	mac, err := net.ParseMAC(val)
+	if err != nil {
+		panic(err)
+	}
+	return mac
+}
+
+func TestGetNetworkAssignment(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "foo",
+			Namespace:   "default",
+			Annotations: networkAnnotations(),
+		},
+	}
+
+	assignment, err := GetNetworkAssignment(pod)
+	assert.NilError(t, err)
+	allocIdx := uint16(7)
+	assert.DeepEqual(t, &NetworkAssignment{
+		IPv4Address:      net.ParseIP("10.0.0.1").To4(),
+		IPv4PrefixLength: intPtr(24),
+		IPv6Address:      net.ParseIP("2600:1f18::1"),
+		IPv6PrefixLength: intPtr(128),
+		BranchENI: &ENI{
+			ID:       "eni-branch",
+			MAC:      mustParseMAC("0a:1b:2c:3d:4e:5f"),
+			VpcID:    "vpc-1",
+			SubnetID: "subnet-1",
+		},
+		TrunkENI: &ENI{
+			ID:    "eni-trunk",
+			MAC:   mustParseMAC("0a:1b:2c:3d:4e:60"),
+			VpcID: "vpc-1",
+		},
+		VlanID:          intPtr(3),
+		AllocationIndex: &allocIdx,
+	}, assignment)
+
+	// Round-trip through SetNetworkAssignment
+	newPod := &corev1.Pod{}
+	assert.NilError(t, SetNetworkAssignment(newPod, assignment))
+	assert.DeepEqual(t, networkAnnotations(), newPod.Annotations)
+}
+
+func TestGetNetworkAssignmentEmpty(t *testing.T) {
+	assignment, err := GetNetworkAssignment(&corev1.Pod{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, &NetworkAssignment{}, assignment)
+}
+
+func TestGetNetworkAssignmentInvalid(t *testing.T) {
+	badAnnotations := []struct {
+		key      string
+		val      string
+		errMatch string
+	}{
+		{
+			key:      AnnotationKeyIPv4Address,
+			val:      "2600:1f18::1",
+			errMatch: "annotation is not a valid IPv4 address: " + AnnotationKeyIPv4Address,
+		},
+		{
+			key:      AnnotationKeyIPv6Address,
+			val:      "10.0.0.1",
+			errMatch: "annotation is not a valid IPv6 address: " + AnnotationKeyIPv6Address,
+		},
+		{
+			key:      AnnotationKeyIPv4PrefixLength,
+			val:      "33",
+			errMatch: "annotation is not a valid prefix length: " + AnnotationKeyIPv4PrefixLength,
+		},
+		{
+			key:      AnnotationKeyIPv6PrefixLength,
+			val:      "/64",
+			errMatch: "annotation is not a valid prefix length: " + AnnotationKeyIPv6PrefixLength,
+		},
+		{
+			key:      AnnotationKeyBranchEniMac,
+			val:      "0a:1b:2c",
+			errMatch: "annotation is not a valid MAC address: " + AnnotationKeyBranchEniMac,
+		},
+		{
+			key:      AnnotationKeyVlanID,
+			val:      "4095",
+			errMatch: "annotation is not a valid VLAN ID: " + AnnotationKeyVlanID,
+		},
+		{
+			key:      AnnotationKeyAllocationIdx,
+			val:      "-1",
+			errMatch: "annotation is not a valid uint16 value: " + AnnotationKeyAllocationIdx,
+		},
+	}
+
+	for _, ba := range badAnnotations {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{ba.key: ba.val},
+			},
+		}
+		_, err := GetNetworkAssignment(pod)
+		assert.ErrorContains(t, err, ba.errMatch)
+	}
+}
+
+func TestSetNetworkAssignmentInvalid(t *testing.T) {
+	pod := &corev1.Pod{}
+	err := SetNetworkAssignment(pod, &NetworkAssignment{
+		IPv4Address: net.ParseIP("2600:1f18::1"),
+		VlanID:      intPtr(0),
+		TrunkENI:    &ENI{ID: "eni-trunk", SubnetID: "subnet-1"},
+	})
+	assert.ErrorContains(t, err, "IPv4 address is not valid: 2600:1f18::1")
+	assert.ErrorContains(t, err, "VLAN ID is not valid: 0")
+	assert.ErrorContains(t, err, "trunk ENI subnet can not be stored on a pod")
+	assert.Assert(t, pod.Annotations == nil)
+}
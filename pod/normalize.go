@@ -0,0 +1,215 @@
+package pod
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// NormalizedPodSchemaVersion is the schema version stamped onto pods that don't declare one.
+// Normalized pods always carry the v1 keys, since legacy keys are upgraded.
+const NormalizedPodSchemaVersion = 1
+
+// PatchOperation is a single RFC 6902 JSON patch operation
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// NormalizationPatch returns the JSON patch that normalizes a pod's labels and annotations:
+//
+// - legacy (schema v0) keys are copied to their v1 equivalents, if those aren't set
+// - comma-separated lists (such as security groups) have whitespace trimmed
+// - the workload and job ID annotations are copied to the labels that duplicate them
+// - the pod schema version is stamped, if it's missing
+//
+// The legacy keys themselves are left in place for older consumers. If the pod is already
+// normalized, the patch is empty.
+func NormalizationPatch(pod *corev1.Pod) []PatchOperation {
+	annotations := copyStringMap(pod.GetAnnotations())
+	labels := copyStringMap(pod.GetLabels())
+
+	upgradeLegacyKeys(annotations, labels)
+
+	listAnnotations := []string{
+		AnnotationKeyNetworkSecurityGroups,
+		AnnotationKeyNetworkSubnetIDs,
+		AnnotationKeyPodTitusSystemEnvVarNames,
+		AnnotationKeySecurityGroupsLegacy,
+		AnnotationKeySubnetsLegacy,
+	}
+	for _, key := range listAnnotations {
+		if val, ok := annotations[key]; ok {
+			annotations[key] = strings.Join(splitList(val), ",")
+		}
+	}
+
+	duplicatedLabels := []struct {
+		annotation string
+		label      string
+	}{
+		{
+			annotation: AnnotationKeyJobID,
+			label:      LabelKeyJobId,
+		},
+		{
+			annotation: AnnotationKeyWorkloadName,
+			label:      LabelKeyWorkloadName,
+		},
+		{
+			annotation: AnnotationKeyWorkloadStack,
+			label:      LabelKeyWorkloadStack,
+		},
+		{
+			annotation: AnnotationKeyWorkloadDetail,
+			label:      LabelKeyWorkloadDetail,
+		},
+		{
+			annotation: AnnotationKeyWorkloadSequence,
+			label:      LabelKeyWorkloadSequence,
+		},
+	}
+	for _, d := range duplicatedLabels {
+		val, ok := annotations[d.annotation]
+		// Values that can't be stored in a label are left for validation to complain about
+		if ok && len(validation.IsValidLabelValue(val)) == 0 {
+			labels[d.label] = val
+		}
+	}
+
+	if _, ok := annotations[AnnotationKeyPodSchemaVersion]; !ok {
+		annotations[AnnotationKeyPodSchemaVersion] = strconv.Itoa(NormalizedPodSchemaVersion)
+	}
+
+	patch := mapPatch("/metadata/annotations", pod.GetAnnotations(), annotations)
+	patch = append(patch, mapPatch("/metadata/labels", pod.GetLabels(), labels)...)
+	return patch
+}
+
+// Copy values from legacy keys to their v1 equivalents, where the v1 key isn't already set
+func upgradeLegacyKeys(annotations, labels map[string]string) {
+	legacyLabels := []struct {
+		legacy     string
+		annotation string
+		label      string
+	}{
+		{
+			legacy:     LabelKeyAppLegacy,
+			annotation: AnnotationKeyWorkloadName,
+		},
+		{
+			legacy:     LabelKeyStackLegacy,
+			annotation: AnnotationKeyWorkloadStack,
+		},
+		{
+			legacy:     LabelKeyDetailLegacy,
+			annotation: AnnotationKeyWorkloadDetail,
+		},
+		{
+			legacy:     LabelKeySequenceLegacy,
+			annotation: AnnotationKeyWorkloadSequence,
+		},
+		{
+			legacy: LabelKeyCapacityGroupLegacy,
+			label:  LabelKeyCapacityGroup,
+		},
+	}
+
+	for _, l := range legacyLabels {
+		val, ok := labels[l.legacy]
+		if !ok {
+			continue
+		}
+		if l.annotation != "" {
+			if _, ok := annotations[l.annotation]; !ok {
+				annotations[l.annotation] = val
+			}
+		}
+		if l.label != "" {
+			if _, ok := labels[l.label]; !ok {
+				labels[l.label] = val
+			}
+		}
+	}
+
+	legacyAnnotations := []struct {
+		legacy string
+		key    string
+	}{
+		{
+			legacy: AnnotationKeySecurityGroupsLegacy,
+			key:    AnnotationKeyNetworkSecurityGroups,
+		},
+		{
+			legacy: AnnotationKeySubnetsLegacy,
+			key:    AnnotationKeyNetworkSubnetIDs,
+		},
+		{
+			legacy: AnnotationKeyAccountIDLegacy,
+			key:    AnnotationKeyNetworkAccountID,
+		},
+	}
+
+	for _, an := range legacyAnnotations {
+		val, ok := annotations[an.legacy]
+		if !ok {
+			continue
+		}
+		if _, ok := annotations[an.key]; !ok {
+			annotations[an.key] = val
+		}
+	}
+}
+
+// Generate the patch operations to turn one string map in a pod into another. Keys are only
+// added or replaced, never removed.
+func mapPatch(path string, current, desired map[string]string) []PatchOperation {
+	patch := []PatchOperation{}
+
+	if len(current) == 0 {
+		if len(desired) == 0 {
+			return patch
+		}
+		// A key can't be added to a map that doesn't exist, so add the whole map
+		return append(patch, PatchOperation{
+			Op:    "add",
+			Path:  path,
+			Value: desired,
+		})
+	}
+
+	keys := []string{}
+	for k := range desired {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		currentVal, ok := current[k]
+		switch {
+		case !ok:
+			patch = append(patch, PatchOperation{Op: "add", Path: path + "/" + escapeJSONPointer(k), Value: desired[k]})
+		case currentVal != desired[k]:
+			patch = append(patch, PatchOperation{Op: "replace", Path: path + "/" + escapeJSONPointer(k), Value: desired[k]})
+		}
+	}
+
+	return patch
+}
+
+// Escape a JSON pointer reference token, as per RFC 6901
+func escapeJSONPointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func copyStringMap(m map[string]string) map[string]string {
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
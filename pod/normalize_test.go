@@ -0,0 +1,75 @@
+package pod
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNormalizationPatch(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "foo",
+			Annotations: map[string]string{
+				AnnotationKeySecurityGroupsLegacy:      "sg-1 , sg-2",
+				AnnotationKeyNetworkSubnetIDs:          " subnet-1, subnet-2 ",
+				AnnotationKeyPodTitusSystemEnvVarNames: "A,B",
+				AnnotationKeyJobID:                     "myjobid",
+			},
+			Labels: map[string]string{
+				LabelKeyAppLegacy:           "myapp",
+				LabelKeyCapacityGroupLegacy: "DEFAULT",
+				LabelKeyJobId:               "stale",
+			},
+		},
+	}
+
+	patch := NormalizationPatch(pod)
+	assert.DeepEqual(t, []PatchOperation{
+		{Op: "add", Path: "/metadata/annotations/network.netflix.com~1security-groups", Value: "sg-1,sg-2"},
+		{Op: "replace", Path: "/metadata/annotations/network.netflix.com~1subnet-ids", Value: "subnet-1,subnet-2"},
+		{Op: "replace", Path: "/metadata/annotations/network.titus.netflix.com~1securityGroups", Value: "sg-1,sg-2"},
+		{Op: "add", Path: "/metadata/annotations/pod.netflix.com~1pod-schema-version", Value: "1"},
+		{Op: "add", Path: "/metadata/annotations/workload.netflix.com~1name", Value: "myapp"},
+		{Op: "add", Path: "/metadata/labels/titus.netflix.com~1capacity-group", Value: "DEFAULT"},
+		{Op: "replace", Path: "/metadata/labels/v3.job.titus.netflix.com~1job-id", Value: "myjobid"},
+		{Op: "add", Path: "/metadata/labels/workload.netflix.com~1name", Value: "myapp"},
+	}, patch)
+}
+
+func TestNormalizationPatchNoMaps(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "foo",
+		},
+	}
+
+	patch := NormalizationPatch(pod)
+	assert.DeepEqual(t, []PatchOperation{
+		{Op: "add", Path: "/metadata/annotations", Value: map[string]string{
+			AnnotationKeyPodSchemaVersion: "1",
+		}},
+	}, patch)
+}
+
+func TestNormalizationPatchNormalized(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "foo",
+			Annotations: map[string]string{
+				AnnotationKeyPodSchemaVersion:      "2",
+				AnnotationKeyNetworkSecurityGroups: "sg-1,sg-2",
+				AnnotationKeyWorkloadName:          "myapp",
+				// Not a valid label value, so it isn't copied
+				AnnotationKeyWorkloadDetail: "my detail",
+			},
+			Labels: map[string]string{
+				LabelKeyWorkloadName: "myapp",
+			},
+		},
+	}
+
+	assert.DeepEqual(t, []PatchOperation{}, NormalizationPatch(pod))
+}
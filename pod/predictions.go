@@ -0,0 +1,231 @@
+package pod
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	multierror "github.com/hashicorp/go-multierror"
+)
+
+// RuntimePrediction contains the job runtime prediction that the scheduler picked for a pod,
+// along with the metadata about how it was picked
+type RuntimePrediction struct {
+	Runtime      *time.Duration
+	Confidence   *float64
+	ModelID      *string
+	ModelVersion *string
+	ABTestCell   *string
+	Available    []PredictedRuntime
+	SelectorInfo *string
+}
+
+// PredictedRuntime is a single runtime prediction at a given confidence (percentile)
+type PredictedRuntime struct {
+	Confidence float64
+	Runtime    time.Duration
+}
+
+// ParsePredictionsAvailable parses the value of the "predictions available" annotation, which is
+// a semicolon-separated list of confidence=runtime pairs, eg: "0.5=30s;0.95=2m". Runtimes are in
+// Go's time.Duration format, though a bare number is accepted as a number of seconds.
+func ParsePredictionsAvailable(val string) ([]PredictedRuntime, error) {
+	predictions := []PredictedRuntime{}
+	val = strings.TrimSpace(val)
+	if val == "" {
+		return predictions, nil
+	}
+
+	for _, entry := range strings.Split(val, ";") {
+		entry = strings.TrimSpace(entry)
+		splitOut := strings.Split(entry, "=")
+		if len(splitOut) != 2 {
+			return nil, fmt.Errorf("prediction is not a confidence=runtime pair: %q", entry)
+		}
+
+		confidence, err := parseConfidence(strings.TrimSpace(splitOut[0]))
+		if err != nil {
+			return nil, fmt.Errorf("prediction has an invalid confidence: %q: %w", entry, err)
+		}
+
+		runtime, err := parsePredictionRuntime(strings.TrimSpace(splitOut[1]))
+		if err != nil {
+			return nil, fmt.Errorf("prediction has an invalid runtime: %q: %w", entry, err)
+		}
+
+		predictions = append(predictions, PredictedRuntime{
+			Confidence: confidence,
+			Runtime:    runtime,
+		})
+	}
+
+	return predictions, nil
+}
+
+// FormatPredictionsAvailable is the inverse of ParsePredictionsAvailable
+func FormatPredictionsAvailable(predictions []PredictedRuntime) string {
+	entries := []string{}
+	for _, p := range predictions {
+		entries = append(entries, strconv.FormatFloat(p.Confidence, 'f', -1, 64)+"="+p.Runtime.String())
+	}
+	return strings.Join(entries, ";")
+}
+
+func parseConfidence(val string) (float64, error) {
+	confidence, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(confidence) || confidence < 0 || confidence > 1 {
+		return 0, fmt.Errorf("confidence must be between 0 and 1: %v", confidence)
+	}
+	return confidence, nil
+}
+
+func parsePredictionRuntime(val string) (time.Duration, error) {
+	runtime, err := time.ParseDuration(val)
+	if err != nil {
+		secs, fErr := strconv.ParseFloat(val, 64)
+		if fErr != nil {
+			return 0, err
+		}
+		if math.IsNaN(secs) || math.IsInf(secs, 0) {
+			return 0, fmt.Errorf("runtime must be a finite number of seconds: %v", secs)
+		}
+		if secs > float64(math.MaxInt64)/float64(time.Second) {
+			return 0, fmt.Errorf("runtime is too long: %v seconds", secs)
+		}
+		runtime = time.Duration(secs * float64(time.Second))
+	}
+	if runtime < 0 {
+		return 0, fmt.Errorf("runtime must not be negative: %s", runtime)
+	}
+	return runtime, nil
+}
+
+// Parse the "predictions.scheduler.titus.netflix.com/*" annotations
+func parsePredictionAnnotations(annotations map[string]string, pConf *Config) error {
+	var err *multierror.Error
+	found := false
+	prediction := RuntimePrediction{}
+
+	stringAnnotations := []struct {
+		key   string
+		field **string
+	}{
+		{
+			key:   AnnotationKeyPredictionModelID,
+			field: &prediction.ModelID,
+		},
+		{
+			key:   AnnotationKeyPredictionModelVersion,
+			field: &prediction.ModelVersion,
+		},
+		{
+			key:   AnnotationKeyPredictionABTestCell,
+			field: &prediction.ABTestCell,
+		},
+		{
+			key:   AnnotationKeyPredictionSelectorInfo,
+			field: &prediction.SelectorInfo,
+		},
+	}
+
+	for _, an := range stringAnnotations {
+		val, ok := annotations[an.key]
+		if ok {
+			found = true
+			*an.field = &val
+		}
+	}
+
+	if val, ok := annotations[AnnotationKeyPredictionRuntime]; ok {
+		found = true
+		runtime, pErr := parsePredictionRuntime(val)
+		if pErr == nil {
+			prediction.Runtime = &runtime
+		} else {
+			err = multierror.Append(err, newFieldError(AnnotationKeyPredictionRuntime, val, KeyTypeDuration, "annotation is not a valid duration value"))
+		}
+	}
+
+	if val, ok := annotations[AnnotationKeyPredictionConfidence]; ok {
+		found = true
+		confidence, pErr := parseConfidence(val)
+		if pErr == nil {
+			prediction.Confidence = &confidence
+		} else {
+			err = multierror.Append(err, newFieldError(AnnotationKeyPredictionConfidence, val, KeyTypeFloat, "annotation is not a valid confidence value"))
+		}
+	}
+
+	if val, ok := annotations[AnnotationKeyPredictionPredictionAvailable]; ok {
+		found = true
+		available, pErr := ParsePredictionsAvailable(val)
+		if pErr == nil {
+			prediction.Available = available
+		} else {
+			fErr := newFieldError(AnnotationKeyPredictionPredictionAvailable, val, KeyTypeStructured, "annotation is not a valid list of predictions")
+			fErr.Err = pErr
+			err = multierror.Append(err, fErr)
+		}
+	}
+
+	if found {
+		pConf.RuntimePrediction = &prediction
+	}
+
+	return err.ErrorOrNil()
+}
+
+// Generate the "predictions.scheduler.titus.netflix.com/*" annotations
+func predictionToAnnotations(prediction *RuntimePrediction) map[string]string {
+	annotations := map[string]string{}
+	if prediction == nil {
+		return annotations
+	}
+
+	stringAnnotations := []struct {
+		key   string
+		field *string
+	}{
+		{
+			key:   AnnotationKeyPredictionModelID,
+			field: prediction.ModelID,
+		},
+		{
+			key:   AnnotationKeyPredictionModelVersion,
+			field: prediction.ModelVersion,
+		},
+		{
+			key:   AnnotationKeyPredictionABTestCell,
+			field: prediction.ABTestCell,
+		},
+		{
+			key:   AnnotationKeyPredictionSelectorInfo,
+			field: prediction.SelectorInfo,
+		},
+	}
+
+	for _, an := range stringAnnotations {
+		if an.field != nil {
+			annotations[an.key] = *an.field
+		}
+	}
+
+	if prediction.Runtime != nil {
+		annotations[AnnotationKeyPredictionRuntime] = prediction.Runtime.String()
+	}
+
+	if prediction.Confidence != nil {
+		annotations[AnnotationKeyPredictionConfidence] = strconv.FormatFloat(*prediction.Confidence, 'f', -1, 64)
+	}
+
+	if prediction.Available != nil {
+		annotations[AnnotationKeyPredictionPredictionAvailable] = FormatPredictionsAvailable(prediction.Available)
+	}
+
+	return annotations
+}
@@ -0,0 +1,81 @@
+package pod
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestParsePredictionsAvailable(t *testing.T) {
+	predictions, err := ParsePredictionsAvailable("0.25=10s; 0.5=1m30s;0.95=300")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []PredictedRuntime{
+		{Confidence: 0.25, Runtime: 10 * time.Second},
+		{Confidence: 0.5, Runtime: 90 * time.Second},
+		{Confidence: 0.95, Runtime: 300 * time.Second},
+	}, predictions)
+
+	assert.Equal(t, FormatPredictionsAvailable(predictions), "0.25=10s;0.5=1m30s;0.95=5m0s")
+
+	predictions, err = ParsePredictionsAvailable("")
+	assert.NilError(t, err)
+	assert.Equal(t, len(predictions), 0)
+}
+
+func TestParsePredictionsAvailableInvalid(t *testing.T) {
+	badValues := []struct {
+		val      string
+		errMatch string
+	}{
+		{
+			val:      "0.5",
+			errMatch: "prediction is not a confidence=runtime pair",
+		},
+		{
+			val:      "0.5=10s;",
+			errMatch: "prediction is not a confidence=runtime pair",
+		},
+		{
+			val:      "1.5=10s",
+			errMatch: "prediction has an invalid confidence",
+		},
+		{
+			val:      "high=10s",
+			errMatch: "prediction has an invalid confidence",
+		},
+		{
+			val:      "0.5=ten",
+			errMatch: "prediction has an invalid runtime",
+		},
+		{
+			val:      "0.5=-10s",
+			errMatch: "prediction has an invalid runtime",
+		},
+		{
+			val:      "NaN=10s",
+			errMatch: "prediction has an invalid confidence",
+		},
+		{
+			val:      "0.5=NaN",
+			errMatch: "prediction has an invalid runtime",
+		},
+		{
+			val:      "0.5=+Inf",
+			errMatch: "prediction has an invalid runtime",
+		},
+		{
+			val:      "0.5=-Inf",
+			errMatch: "prediction has an invalid runtime",
+		},
+		{
+			val:      "0.5=1e300",
+			errMatch: "prediction has an invalid runtime",
+		},
+	}
+
+	for _, bv := range badValues {
+		_, err := ParsePredictionsAvailable(bv.val)
+		assert.ErrorContains(t, err, bv.errMatch)
+	}
+}
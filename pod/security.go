@@ -0,0 +1,155 @@
+package pod
+
+import (
+	"errors"
+	"sort"
+
+	multierror "github.com/hashicorp/go-multierror"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// CapabilitySysAdmin is the capability FUSE mounts need
+	CapabilitySysAdmin = "SYS_ADMIN"
+	// FieldKeyCapabilities is the FieldError key for errors in a container's capabilities, which
+	// aren't set by an annotation. The capability is the error's value.
+	FieldKeyCapabilities = "securityContext.capabilities"
+)
+
+// Read the workload container's security context (falling back to the pod's for the seccomp
+// profile), and the pod's sysctls
+func parseSecurityContext(pod *corev1.Pod, workloadContainer *corev1.Container, pConf *Config) {
+	if sc := workloadContainer.SecurityContext; sc != nil {
+		if sc.Capabilities != nil {
+			pConf.CapabilitiesAdd = capabilitiesToStrings(sc.Capabilities.Add)
+			pConf.CapabilitiesDrop = capabilitiesToStrings(sc.Capabilities.Drop)
+		}
+		if sc.SeccompProfile != nil {
+			pConf.SeccompProfile = sc.SeccompProfile.DeepCopy()
+		}
+	}
+
+	podSC := pod.Spec.SecurityContext
+	if podSC == nil {
+		return
+	}
+	if pConf.SeccompProfile == nil && podSC.SeccompProfile != nil {
+		pConf.SeccompProfile = podSC.SeccompProfile.DeepCopy()
+	}
+	if len(podSC.Sysctls) > 0 {
+		pConf.Sysctls = map[string]string{}
+		for _, s := range podSC.Sysctls {
+			pConf.Sysctls[s.Name] = s.Value
+		}
+	}
+}
+
+func capabilitiesToStrings(caps []corev1.Capability) []string {
+	if caps == nil {
+		return nil
+	}
+	strs := []string{}
+	for _, c := range caps {
+		strs = append(strs, string(c))
+	}
+	return strs
+}
+
+func stringsToCapabilities(strs []string) []corev1.Capability {
+	if strs == nil {
+		return nil
+	}
+	caps := []corev1.Capability{}
+	for _, s := range strs {
+		caps = append(caps, corev1.Capability(s))
+	}
+	return caps
+}
+
+// ValidateSecurityContext checks that the workload's security context allows the features enabled
+// by annotations: FUSE needs the SYS_ADMIN capability, and the seccomp agents need a seccomp
+// profile. It also checks that no capability is both added and dropped. PodToConfig doesn't run
+// these checks, so that pods that ran before they were added still parse; the validating
+// admission webhook runs them on new pods.
+func (c *Config) ValidateSecurityContext() error {
+	var err *multierror.Error
+
+	workloadName := ""
+	for _, ctr := range c.Containers {
+		if ctr.Role == ContainerRoleWorkload {
+			workloadName = ctr.Name
+		}
+	}
+
+	for _, capability := range c.CapabilitiesAdd {
+		if containsString(c.CapabilitiesDrop, capability) {
+			fErr := newFieldError(FieldKeyCapabilities, capability, KeyTypeString, "capability is both added and dropped")
+			fErr.Container = workloadName
+			fErr.Err = errors.New(capability)
+			err = multierror.Append(err, fErr)
+		}
+	}
+
+	if c.FuseEnabled != nil && *c.FuseEnabled && !containsString(c.CapabilitiesAdd, CapabilitySysAdmin) {
+		err = multierror.Append(err, newFieldError(AnnotationKeyPodFuseEnabled, "true", KeyTypeBool,
+			"annotation requires the "+CapabilitySysAdmin+" capability"))
+	}
+
+	hasSeccompProfile := c.SeccompProfile != nil && c.SeccompProfile.Type != corev1.SeccompProfileTypeUnconfined
+	seccompAgents := []struct {
+		key   string
+		field *bool
+	}{
+		{
+			key:   AnnotationKeyPodSeccompAgentNetEnabled,
+			field: c.SeccompAgentNetEnabled,
+		},
+		{
+			key:   AnnotationKeyPodSeccompAgentPerfEnabled,
+			field: c.SeccompAgentPerfEnabled,
+		},
+	}
+	for _, sa := range seccompAgents {
+		if sa.field != nil && *sa.field && !hasSeccompProfile {
+			err = multierror.Append(err, newFieldError(sa.key, "true", KeyTypeBool, "annotation requires a seccomp profile"))
+		}
+	}
+
+	return err.ErrorOrNil()
+}
+
+// Write the security context fields of a config onto the workload container and pod
+func applySecurityContext(pod *corev1.Pod, workloadContainer *corev1.Container, pConf *Config) {
+	if pConf.CapabilitiesAdd != nil || pConf.CapabilitiesDrop != nil || pConf.SeccompProfile != nil {
+		if workloadContainer.SecurityContext == nil {
+			workloadContainer.SecurityContext = &corev1.SecurityContext{}
+		}
+		sc := workloadContainer.SecurityContext
+		if pConf.CapabilitiesAdd != nil || pConf.CapabilitiesDrop != nil {
+			sc.Capabilities = &corev1.Capabilities{
+				Add:  stringsToCapabilities(pConf.CapabilitiesAdd),
+				Drop: stringsToCapabilities(pConf.CapabilitiesDrop),
+			}
+		}
+		if pConf.SeccompProfile != nil {
+			sc.SeccompProfile = pConf.SeccompProfile.DeepCopy()
+		}
+	}
+
+	if pConf.Sysctls != nil {
+		if pod.Spec.SecurityContext == nil {
+			pod.Spec.SecurityContext = &corev1.PodSecurityContext{}
+		}
+		names := []string{}
+		for name := range pConf.Sysctls {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		sysctls := []corev1.Sysctl{}
+		for _, name := range names {
+			sysctls = append(sysctls, corev1.Sysctl{Name: name, Value: pConf.Sysctls[name]})
+		}
+		pod.Spec.SecurityContext.Sysctls = sysctls
+	}
+}
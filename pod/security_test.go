@@ -0,0 +1,104 @@
+package pod
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	ptr "k8s.io/utils/pointer"
+)
+
+func TestParseSecurityContext(t *testing.T) {
+	pod := buildPod(map[string]string{}, map[string]string{})
+	pod.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{
+		Capabilities: &corev1.Capabilities{
+			Add:  []corev1.Capability{"SYS_ADMIN"},
+			Drop: []corev1.Capability{"NET_RAW"},
+		},
+		SeccompProfile: &corev1.SeccompProfile{
+			Type:             corev1.SeccompProfileTypeLocalhost,
+			LocalhostProfile: ptr.StringPtr("default.json"),
+		},
+	}
+	pod.Spec.SecurityContext = &corev1.PodSecurityContext{
+		Sysctls: []corev1.Sysctl{
+			{Name: "net.ipv4.conf.all.accept_local", Value: "1"},
+			{Name: "net.ipv4.conf.all.route_localnet", Value: "1"},
+		},
+	}
+
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"SYS_ADMIN"}, conf.CapabilitiesAdd)
+	assert.DeepEqual(t, []string{"NET_RAW"}, conf.CapabilitiesDrop)
+	assert.DeepEqual(t, &corev1.SeccompProfile{
+		Type:             corev1.SeccompProfileTypeLocalhost,
+		LocalhostProfile: ptr.StringPtr("default.json"),
+	}, conf.SeccompProfile)
+	assert.DeepEqual(t, map[string]string{
+		"net.ipv4.conf.all.accept_local":   "1",
+		"net.ipv4.conf.all.route_localnet": "1",
+	}, conf.Sysctls)
+}
+
+func TestSecurityContextValidation(t *testing.T) {
+	pod := buildPod(map[string]string{
+		AnnotationKeyPodFuseEnabled:            "true",
+		AnnotationKeyPodSeccompAgentNetEnabled: "true",
+	}, map[string]string{})
+
+	// Parsing doesn't check the security context, so existing pods still parse
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+	err = conf.ValidateSecurityContext()
+	assert.ErrorContains(t, err, "annotation requires the SYS_ADMIN capability: "+AnnotationKeyPodFuseEnabled)
+	assert.ErrorContains(t, err, "annotation requires a seccomp profile: "+AnnotationKeyPodSeccompAgentNetEnabled)
+
+	pod.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{
+		Capabilities: &corev1.Capabilities{
+			Add:  []corev1.Capability{"SYS_ADMIN"},
+			Drop: []corev1.Capability{"NET_RAW"},
+		},
+		SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+	conf, err = PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.NilError(t, conf.ValidateSecurityContext())
+
+	sc := pod.Spec.Containers[0].SecurityContext
+	sc.Capabilities.Add = []corev1.Capability{"NET_ADMIN", "NET_RAW"}
+	sc.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeUnconfined}
+
+	conf, err = PodToConfig(pod)
+	assert.NilError(t, err)
+	err = conf.ValidateSecurityContext()
+	assert.ErrorContains(t, err, "capability is both added and dropped: "+FieldKeyCapabilities+": NET_RAW")
+
+	fieldErrs := FieldErrors(err)
+	assert.Equal(t, len(fieldErrs), 3)
+	assert.Equal(t, fieldErrs[0].Key, FieldKeyCapabilities)
+	assert.Equal(t, fieldErrs[0].Value, "NET_RAW")
+	assert.Equal(t, fieldErrs[0].Container, "task-id-in-container")
+	assert.Equal(t, fieldErrs[1].Key, AnnotationKeyPodFuseEnabled)
+	assert.Equal(t, fieldErrs[2].Key, AnnotationKeyPodSeccompAgentNetEnabled)
+}
+
+func TestSecurityContextPodSeccompProfile(t *testing.T) {
+	pod := buildPod(map[string]string{
+		AnnotationKeyPodSeccompAgentPerfEnabled: "true",
+	}, map[string]string{})
+	pod.Spec.SecurityContext = &corev1.PodSecurityContext{
+		SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.Assert(t, conf.CapabilitiesAdd == nil)
+	assert.Equal(t, conf.SeccompProfile.Type, corev1.SeccompProfileTypeRuntimeDefault)
+	assert.NilError(t, conf.ValidateSecurityContext())
+
+	pod.Spec.SecurityContext = nil
+	conf, err = PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.ErrorContains(t, conf.ValidateSecurityContext(), "annotation requires a seccomp profile: "+AnnotationKeyPodSeccompAgentPerfEnabled)
+}
@@ -0,0 +1,176 @@
+package pod
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	multierror "github.com/hashicorp/go-multierror"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// Maximum edit distance for a known key to be suggested in place of an unknown one
+	maxSuggestionDistance = 3
+	maxSuggestions        = 3
+)
+
+// UnknownKey is a label or annotation in a Titus-owned domain that isn't a known key
+type UnknownKey struct {
+	Key string
+	// Label is true if the key is a label, and false if it's an annotation
+	Label bool
+	// Suggestions are the known keys closest to Key, best match first
+	Suggestions []string
+}
+
+func (u UnknownKey) Error() string {
+	kind := "annotation"
+	if u.Label {
+		kind = "label"
+	}
+
+	msg := fmt.Sprintf("%s is not a known Titus key: %s", kind, u.Key)
+	if len(u.Suggestions) > 0 {
+		msg += fmt.Sprintf(" (did you mean %s?)", strings.Join(u.Suggestions, " or "))
+	}
+	return msg
+}
+
+// Is the key in one of the domains owned by Titus? That's DomainNetflix and all of its
+// subdomains, such as DomainTitus, DomainPod, network.netflix.com and log.netflix.com.
+func isTitusDomainKey(key string) bool {
+	idx := strings.Index(key, "/")
+	if idx < 0 {
+		return false
+	}
+	domain := key[:idx]
+	return domain == DomainNetflix || strings.HasSuffix(domain, "."+DomainNetflix)
+}
+
+// FindUnknownKeys returns the labels and annotations in Titus-owned domains that aren't known
+// keys, along with suggestions for what they may have been meant to be. Keys outside of those
+// domains are ignored. Annotations are returned before labels, each sorted by key.
+func FindUnknownKeys(pod *corev1.Pod) []UnknownKey {
+	unknown := []UnknownKey{}
+
+	annotationKeys := []string{}
+	for _, spec := range annotationCatalog {
+		if !spec.Prefix {
+			annotationKeys = append(annotationKeys, spec.Key)
+		}
+	}
+	for _, key := range sortedKeys(pod.GetAnnotations()) {
+		if !isTitusDomainKey(key) {
+			continue
+		}
+		if _, ok := LookupAnnotation(key); ok {
+			continue
+		}
+		unknown = append(unknown, UnknownKey{
+			Key:         key,
+			Suggestions: suggestKeys(key, annotationKeys),
+		})
+	}
+
+	labelKeys := []string{}
+	for _, info := range labelCatalog {
+		labelKeys = append(labelKeys, info.Key)
+	}
+	for _, key := range sortedKeys(pod.GetLabels()) {
+		if !isTitusDomainKey(key) {
+			continue
+		}
+		if _, ok := LookupLabel(key); ok {
+			continue
+		}
+		unknown = append(unknown, UnknownKey{
+			Key:         key,
+			Label:       true,
+			Suggestions: suggestKeys(key, labelKeys),
+		})
+	}
+
+	return unknown
+}
+
+// PodToConfigStrict is PodToConfig, but also returns an error for every label and annotation in a
+// Titus-owned domain that isn't a known key, so that typos aren't silently ignored
+func PodToConfigStrict(pod *corev1.Pod) (*Config, error) {
+	pConf, err := PodToConfig(pod)
+	var mErr *multierror.Error
+	if err != nil {
+		mErr = multierror.Append(mErr, err)
+	}
+
+	for _, u := range FindUnknownKeys(pod) {
+		mErr = multierror.Append(mErr, u)
+	}
+
+	return pConf, mErr.ErrorOrNil()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Return the known keys within maxSuggestionDistance edits of key, closest first
+func suggestKeys(key string, known []string) []string {
+	type candidate struct {
+		key      string
+		distance int
+	}
+
+	candidates := []candidate{}
+	for _, k := range known {
+		if d := editDistance(key, k); d <= maxSuggestionDistance {
+			candidates = append(candidates, candidate{key: k, distance: d})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].key < candidates[j].key
+	})
+
+	suggestions := []string{}
+	for i := 0; i < len(candidates) && i < maxSuggestions; i++ {
+		suggestions = append(suggestions, candidates[i].key)
+	}
+	return suggestions
+}
+
+// Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,90 @@
+package pod
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFindUnknownKeys(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				AnnotationKeyPodCPUBurstingEnabled:                   "true",
+				"pod.netflix.com/cpu-bursting-enable":                "true",
+				"log.netflix.com/upload-regex":                       ".*",
+				"network.netflix.com/something-completely-different": "foo",
+				AnnotationKeyServicePrefix + "/servicemesh.v1.image": "titusops/servicemesh:latest",
+				AnnotationKeyPrefixContainerType + "sidecar":         AnnotationValueContainerTypePlatformSidecar,
+				"example.com/not-titus":                              "foo",
+				"netflixxcom/not-titus":                              "foo",
+			},
+			Labels: map[string]string{
+				LabelKeyJobId:                      "job-id",
+				"v3.job.titus.netflix.com/task-ig": "task-id",
+				"app":                              "foo",
+			},
+		},
+	}
+
+	unknown := FindUnknownKeys(pod)
+	assert.DeepEqual(t, unknown, []UnknownKey{
+		{
+			Key:         "log.netflix.com/upload-regex",
+			Suggestions: []string{AnnotationKeyLogUploadRegexp},
+		},
+		{
+			Key:         "network.netflix.com/something-completely-different",
+			Suggestions: []string{},
+		},
+		{
+			Key:         "pod.netflix.com/cpu-bursting-enable",
+			Suggestions: []string{AnnotationKeyPodCPUBurstingEnabled},
+		},
+		{
+			Key:         "v3.job.titus.netflix.com/task-ig",
+			Label:       true,
+			Suggestions: []string{LabelKeyTaskId},
+		},
+	})
+
+	assert.Equal(t, unknown[2].Error(), "annotation is not a known Titus key: pod.netflix.com/cpu-bursting-enable (did you mean pod.netflix.com/cpu-bursting-enabled?)")
+	assert.Equal(t, unknown[1].Error(), "annotation is not a known Titus key: network.netflix.com/something-completely-different")
+}
+
+func TestPodToConfigStrict(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				AnnotationKeyPodSchedPolicy:           "bogus",
+				"pod.netflix.com/cpu-bursting-enable": "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "main"}},
+		},
+	}
+
+	// Unknown keys are ignored by default
+	_, err := PodToConfig(pod)
+	assert.Error(t, err, "1 error occurred:\n\t* annotation is not a valid scheduler policy: pod.netflix.com/sched-policy\n\n")
+
+	_, err = PodToConfigStrict(pod)
+	assert.ErrorContains(t, err, "2 errors occurred")
+	assert.ErrorContains(t, err, "annotation is not a valid scheduler policy: "+AnnotationKeyPodSchedPolicy)
+	assert.ErrorContains(t, err, "did you mean "+AnnotationKeyPodCPUBurstingEnabled)
+
+	delete(pod.Annotations, AnnotationKeyPodSchedPolicy)
+	delete(pod.Annotations, "pod.netflix.com/cpu-bursting-enable")
+	_, err = PodToConfigStrict(pod)
+	assert.NilError(t, err)
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, editDistance("", ""), 0)
+	assert.Equal(t, editDistance("abc", ""), 3)
+	assert.Equal(t, editDistance("kitten", "sitting"), 3)
+	assert.Equal(t, editDistance("flaw", "lawn"), 2)
+}
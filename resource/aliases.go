@@ -0,0 +1,88 @@
+package resource
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Aliases maps each resource name to all of the names it may be specified with, in order of
+// precedence. The first name is the canonical one.
+var Aliases = map[string][]string{
+	ResourceNameCpu:     {ResourceNameCpu},
+	ResourceNameMemory:  {ResourceNameMemory},
+	ResourceNameDisk:    {ResourceNameDisk, ResourceNameDiskLegacy},
+	ResourceNameGpu:     {ResourceNameGpu, ResourceNameNvidiaGpu, ResourceNameGpuLegacy},
+	ResourceNameNetwork: {ResourceNameNetwork, ResourceNameNetworkLegacy},
+}
+
+// LegacyNames maps each resource name to the legacy names it may also be specified with. Other
+// aliases, like nvidia.com/gpu (the extended resource the NVIDIA device plugin schedules on), are
+// not legacy names.
+var LegacyNames = map[string][]string{
+	ResourceNameDisk:    {ResourceNameDiskLegacy},
+	ResourceNameGpu:     {ResourceNameGpuLegacy},
+	ResourceNameNetwork: {ResourceNameNetworkLegacy},
+}
+
+// CanonicalName returns the canonical name of a resource, given any of its aliases. Names that
+// aren't aliases of a known resource are returned unchanged.
+func CanonicalName(name string) string {
+	for canonical, aliases := range Aliases {
+		for _, alias := range aliases {
+			if alias == name {
+				return canonical
+			}
+		}
+	}
+	return name
+}
+
+// ConflictError is returned by GetQuantity when two of a resource's aliases have different values
+type ConflictError struct {
+	// Name and Value are the alias that conflicts with the one that takes precedence
+	Name  string
+	Value resource.Quantity
+	// ConflictsWith and ConflictsWithValue are the alias that takes precedence
+	ConflictsWith      string
+	ConflictsWithValue resource.Quantity
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("resource %s conflicts with %s: %s != %s", e.Name, e.ConflictsWith, e.Value.String(), e.ConflictsWithValue.String())
+}
+
+// GetQuantity looks up a resource in a resource list under any of its aliases. If more than one
+// alias is set, the one with the highest precedence is returned, and it's an error for the others
+// to have a different value (a *ConflictError). Returns nil if none of the aliases are set.
+func GetQuantity(resources corev1.ResourceList, name string) (*resource.Quantity, error) {
+	aliases, ok := Aliases[CanonicalName(name)]
+	if !ok {
+		aliases = []string{name}
+	}
+
+	var found *resource.Quantity
+	foundName := ""
+	for _, alias := range aliases {
+		q, ok := resources[corev1.ResourceName(alias)]
+		if !ok {
+			continue
+		}
+		if found == nil {
+			found = &q
+			foundName = alias
+			continue
+		}
+		if q.Cmp(*found) != 0 {
+			return nil, &ConflictError{
+				Name:               alias,
+				Value:              q,
+				ConflictsWith:      foundName,
+				ConflictsWithValue: *found,
+			}
+		}
+	}
+
+	return found, nil
+}
@@ -0,0 +1,66 @@
+package resource
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestCanonicalName(t *testing.T) {
+	assert.Equal(t, CanonicalName(ResourceNameNvidiaGpu), ResourceNameGpu)
+	assert.Equal(t, CanonicalName(ResourceNameGpuLegacy), ResourceNameGpu)
+	assert.Equal(t, CanonicalName(ResourceNameNetworkLegacy), ResourceNameNetwork)
+	assert.Equal(t, CanonicalName(ResourceNameDiskLegacy), ResourceNameDisk)
+	assert.Equal(t, CanonicalName(ResourceNameCpu), ResourceNameCpu)
+	assert.Equal(t, CanonicalName("example.com/widgets"), "example.com/widgets")
+}
+
+func TestGetQuantity(t *testing.T) {
+	resources := corev1.ResourceList{
+		ResourceNameNvidiaGpu:     resource.MustParse("1"),
+		ResourceNameGpuLegacy:     resource.MustParse("1"),
+		ResourceNameNetworkLegacy: resource.MustParse("128M"),
+		ResourceNameDisk:          resource.MustParse("10Gi"),
+		ResourceNameDiskLegacy:    resource.MustParse("10240Mi"),
+		"example.com/widgets":     resource.MustParse("3"),
+	}
+
+	gpu, err := GetQuantity(resources, ResourceNameGpu)
+	assert.NilError(t, err)
+	assert.Equal(t, gpu.String(), "1")
+
+	network, err := GetQuantity(resources, ResourceNameNetwork)
+	assert.NilError(t, err)
+	assert.Equal(t, network.String(), "128M")
+
+	// Equal values in different formats don't conflict, and the canonical name wins
+	disk, err := GetQuantity(resources, ResourceNameDiskLegacy)
+	assert.NilError(t, err)
+	assert.Equal(t, disk.String(), "10Gi")
+
+	widgets, err := GetQuantity(resources, "example.com/widgets")
+	assert.NilError(t, err)
+	assert.Equal(t, widgets.String(), "3")
+
+	cpu, err := GetQuantity(resources, ResourceNameCpu)
+	assert.NilError(t, err)
+	assert.Assert(t, cpu == nil)
+}
+
+func TestGetQuantityConflict(t *testing.T) {
+	resources := corev1.ResourceList{
+		ResourceNameGpu:       resource.MustParse("1"),
+		ResourceNameNvidiaGpu: resource.MustParse("2"),
+	}
+
+	_, err := GetQuantity(resources, ResourceNameGpu)
+	assert.Error(t, err, "resource nvidia.com/gpu conflicts with titus/gpu: 2 != 1")
+
+	cErr, ok := err.(*ConflictError)
+	assert.Assert(t, ok)
+	assert.Equal(t, cErr.Name, ResourceNameNvidiaGpu)
+	assert.Equal(t, cErr.Value.String(), "2")
+	assert.Equal(t, cErr.ConflictsWith, ResourceNameGpu)
+}
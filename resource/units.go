@@ -0,0 +1,48 @@
+package resource
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// Without byte units, the control plane sends memory and disk in MiB
+	legacyBytesPerUnit = 1024 * 1024
+	// Without byte units, the control plane sends network bandwidth in Mbps
+	legacyBitsPerUnit = 1000 * 1000
+)
+
+// ToBytes converts a memory or disk quantity sent by the control plane to bytes. If byteUnits
+// is false (the pod doesn't have the byte units label), a plain number of less than 1Mi is a
+// count of MiB, and is scaled. Other quantities, such as 512Mi or 10Gi, are already in bytes.
+// The API server writes large numbers with a decimal suffix, so 10000 MiB reads back as 10k,
+// and is still scaled.
+func ToBytes(q resource.Quantity, byteUnits bool) resource.Quantity {
+	if byteUnits || !isLegacyCount(q, legacyBytesPerUnit) {
+		return q.DeepCopy()
+	}
+	return *resource.NewQuantity(scaleLegacyCount(q, legacyBytesPerUnit), resource.BinarySI)
+}
+
+// ToBitsPerSecond converts a network bandwidth quantity sent by the control plane to bits/sec.
+// If byteUnits is false (the pod doesn't have the byte units label), a plain number of less
+// than 1M is a count of Mbps, and is scaled. Other quantities, such as 128M, are already in
+// bits/sec.
+func ToBitsPerSecond(q resource.Quantity, byteUnits bool) resource.Quantity {
+	if byteUnits || !isLegacyCount(q, legacyBitsPerUnit) {
+		return q.DeepCopy()
+	}
+	return *resource.NewQuantity(scaleLegacyCount(q, legacyBitsPerUnit), resource.DecimalSI)
+}
+
+// isLegacyCount returns true if a quantity is a count of legacy units, rather than already
+// being in bytes or bits/sec. Binary suffixes (Mi, Gi) and exponents are never used for counts,
+// and a count is always less than one unit's worth of bytes or bits/sec.
+func isLegacyCount(q resource.Quantity, unit int64) bool {
+	return q.Format == resource.DecimalSI && q.Cmp(*resource.NewQuantity(unit, resource.DecimalSI)) < 0
+}
+
+// scaleLegacyCount converts a count of legacy units. Since the count is less than unit, this
+// can't overflow.
+func scaleLegacyCount(q resource.Quantity, unit int64) int64 {
+	return q.MilliValue() * unit / 1000
+}
@@ -0,0 +1,55 @@
+package resource
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestToBytes(t *testing.T) {
+	q := ToBytes(resource.MustParse("512"), false)
+	assert.Equal(t, q.Value(), int64(512*1024*1024))
+	assert.Equal(t, q.String(), "512Mi")
+
+	q = ToBytes(resource.MustParse("0.5"), false)
+	assert.Equal(t, q.Value(), int64(512*1024))
+
+	// The API server writes 10000 as 10k
+	q = ToBytes(resource.MustParse("10k"), false)
+	assert.Equal(t, q.Value(), int64(10000*1024*1024))
+
+	// Quantities with a binary suffix, or of at least 1Mi, are already in bytes
+	q = ToBytes(resource.MustParse("512Mi"), false)
+	assert.Equal(t, q.String(), "512Mi")
+	q = ToBytes(resource.MustParse("10Gi"), false)
+	assert.Equal(t, q.Value(), int64(10*1024*1024*1024))
+	q = ToBytes(resource.MustParse("512M"), false)
+	assert.Equal(t, q.String(), "512M")
+	q = ToBytes(resource.MustParse("1Pi"), false)
+	assert.Equal(t, q.String(), "1Pi")
+
+	q = ToBytes(resource.MustParse("512Mi"), true)
+	assert.Equal(t, q.Value(), int64(512*1024*1024))
+
+	q = ToBytes(resource.MustParse("536870912"), true)
+	assert.Equal(t, q.String(), "536870912")
+}
+
+func TestToBitsPerSecond(t *testing.T) {
+	q := ToBitsPerSecond(resource.MustParse("128"), false)
+	assert.Equal(t, q.Value(), int64(128000000))
+	assert.Equal(t, q.String(), "128M")
+
+	q = ToBitsPerSecond(resource.MustParse("10k"), false)
+	assert.Equal(t, q.String(), "10G")
+
+	// Quantities of at least 1M are already in bits/sec
+	q = ToBitsPerSecond(resource.MustParse("128M"), false)
+	assert.Equal(t, q.String(), "128M")
+	q = ToBitsPerSecond(resource.MustParse("100G"), false)
+	assert.Equal(t, q.Value(), int64(100000000000))
+
+	q = ToBitsPerSecond(resource.MustParse("128M"), true)
+	assert.Equal(t, q.Value(), int64(128000000))
+}